@@ -1,8 +1,9 @@
 package acmcsuf
 
 import (
+	"encoding/json"
 	"fmt"
-	"strconv"
+	"reflect"
 )
 
 // Constants for paths that point to the JSON files from the acmcsuf.com
@@ -15,8 +16,9 @@ const (
 // Officers returns the officers for the given term.
 type Officers []Officer
 
-// Find returns the officer with the given name.
-func (o Officers) Find(f func(*Officer) bool) *Officer {
+// Find returns the first officer that matches or nil if none does. Use
+// FindOne to make sure that the match is unambiguous.
+func (o Officers) Find(f Matcher) *Officer {
 	for i := range o {
 		if f(&o[i]) {
 			return &o[i]
@@ -31,88 +33,94 @@ type Officer struct {
 	Picture  string               `json:"picture"`
 	Socials  Socials              `json:"socials"`
 	Terms    map[Term]OfficerTerm `json:"terms"`
+	// Extra holds the fields that this package doesn't know about, such as
+	// "bio".
+	Extra Extra `json:"-"`
 }
 
-// Socials is an object of social media platforms to their URLs.
-type Socials struct {
-	Website   string `json:"website"`
-	GitHub    string `json:"github"`
-	Discord   string `json:"discord"`
-	LinkedIn  string `json:"linkedin"`
-	Instagram string `json:"instagram"`
-}
-
-// OfficerTerm represents a term of an officer.
-type OfficerTerm struct {
-	Title string // value in OfficerTiers
-	Tier  int    // index in OfficerTiers
-}
-
-// Tiers is a list of known officer tiers, which is all the officer positions
-// used for the order (sort of like a pyramid of hierarchy).
-//
-// The usefulness of this type and the file (tiers.json) is debatable.
-type Tiers []string
-
-// Term represents a term of an officer.
-type Term string
-
-// NewTerm returns a new term from the given semester and year.
-func NewTerm(semester Semester, year int) Term {
-	return Term(fmt.Sprintf("%s%d", semester, year))
-}
-
-// Semester returns the semester of the term.
-func (t Term) Semester() Semester {
-	if len(t) < 1 {
-		return ""
+// UnmarshalJSON implements json.Unmarshaler. Unknown fields are kept in Extra.
+func (o *Officer) UnmarshalJSON(b []byte) error {
+	type raw Officer
+	if err := json.Unmarshal(b, (*raw)(o)); err != nil {
+		return err
 	}
-	return Semester(t[:1])
-}
 
-// Year returns the year of the term.
-func (t Term) Year() int {
-	if len(t) < 2 {
-		return 0
+	extra, err := unmarshalExtra(b, reflect.TypeOf(raw{}))
+	if err != nil {
+		return err
 	}
 
-	year, err := strconv.Atoi(string(t[1:]))
+	o.Extra = extra
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. Fields in Extra are written after the
+// known fields.
+func (o Officer) MarshalJSON() ([]byte, error) {
+	type raw Officer
+	b, err := json.Marshal(raw(o))
 	if err != nil {
-		return 0
+		return nil, err
 	}
+	return marshalExtra(b, o.Extra)
+}
 
-	return year
+// Socials is an object of social media platforms to their URLs. Platforms
+// that are empty are left out of the JSON, so clearing one removes its key.
+type Socials struct {
+	Website   string `json:"website,omitempty"`
+	GitHub    string `json:"github,omitempty"`
+	Discord   string `json:"discord,omitempty"`
+	LinkedIn  string `json:"linkedin,omitempty"`
+	Instagram string `json:"instagram,omitempty"`
+	// DiscordID is the ID of the officer's Discord account. Unlike Discord,
+	// which is only the handle for display, it never changes, so it's what
+	// officers should be looked up by.
+	DiscordID string `json:"discordId,omitempty"`
+	// Extra holds the platforms that this package doesn't know about.
+	Extra Extra `json:"-"`
 }
 
-// Validate returns an error if the term is invalid.
-func (t Term) Validate() error {
-	if t.Semester() == "" {
-		return fmt.Errorf("invalid semester: %q", t)
+// UnmarshalJSON implements json.Unmarshaler. Unknown fields are kept in Extra.
+func (s *Socials) UnmarshalJSON(b []byte) error {
+	type raw Socials
+	if err := json.Unmarshal(b, (*raw)(s)); err != nil {
+		return err
 	}
 
-	if t.Year() == 0 {
-		return fmt.Errorf("invalid year: %q", t)
+	extra, err := unmarshalExtra(b, reflect.TypeOf(raw{}))
+	if err != nil {
+		return err
 	}
 
+	s.Extra = extra
 	return nil
 }
 
-// Semester represents a semester.
-type Semester string
+// MarshalJSON implements json.Marshaler. Fields in Extra are written after the
+// known fields.
+func (s Socials) MarshalJSON() ([]byte, error) {
+	type raw Socials
+	b, err := json.Marshal(raw(s))
+	if err != nil {
+		return nil, err
+	}
+	return marshalExtra(b, s.Extra)
+}
 
-const (
-	Fall   Semester = "F"
-	Spring Semester = "S"
-)
+// OfficerTerm represents a term of an officer.
+type OfficerTerm struct {
+	Title string `json:"title"` // value in Tiers
+	Tier  int    `json:"tier"`  // index in Tiers
+}
 
-// String returns the human representation of the term.
-func (s Semester) String() string {
-	switch s {
-	case Fall:
-		return "Fall"
-	case Spring:
-		return "Spring"
-	default:
-		return fmt.Sprintf("Semester(%q)", string(s))
-	}
+// String formats the term as "Title (tier N)".
+func (t OfficerTerm) String() string {
+	return fmt.Sprintf("%s (tier %d)", t.Title, t.Tier)
 }
+
+// Tiers is a list of known officer tiers, which is all the officer positions
+// used for the order (sort of like a pyramid of hierarchy).
+//
+// The usefulness of this type and the file (tiers.json) is debatable.
+type Tiers []string
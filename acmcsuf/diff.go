@@ -0,0 +1,147 @@
+package acmcsuf
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Change is a single change between two versions of officers.
+type Change struct {
+	// Officer is the full name of the officer.
+	Officer string
+	// Field is the path to the changed field, e.g. "socials.github". It is
+	// empty if the whole officer was added or removed.
+	Field string
+	// Old and New are the old and new values of the field. An empty string
+	// means that the value was unset. For added or removed officers, these
+	// are the officer's full name.
+	Old string
+	New string
+}
+
+// Kind returns a short description of the kind of change.
+func (c Change) Kind() string {
+	switch {
+	case c.Old == "":
+		return "add"
+	case c.New == "":
+		return "remove"
+	default:
+		return "change"
+	}
+}
+
+// String formats the change in a human-readable way.
+func (c Change) String() string {
+	if c.Field == "" {
+		switch c.Kind() {
+		case "add":
+			return fmt.Sprintf("add officer %s", c.Officer)
+		default:
+			return fmt.Sprintf("remove officer %s", c.Officer)
+		}
+	}
+
+	switch c.Kind() {
+	case "add":
+		return fmt.Sprintf("%s: set %s to %q", c.Officer, c.Field, c.New)
+	case "remove":
+		return fmt.Sprintf("%s: unset %s (was %q)", c.Officer, c.Field, c.Old)
+	default:
+		return fmt.Sprintf("%s: change %s from %q to %q", c.Officer, c.Field, c.Old, c.New)
+	}
+}
+
+// DiffOfficers returns the list of changes needed to go from old to new.
+// Officers are matched by their full names.
+func DiffOfficers(old, new Officers) []Change {
+	oldMap := officersByName(old)
+	newMap := officersByName(new)
+
+	var changes []Change
+
+	for _, officer := range old {
+		if _, ok := newMap[officer.FullName]; !ok {
+			changes = append(changes, Change{
+				Officer: officer.FullName,
+				Old:     officer.FullName,
+			})
+		}
+	}
+
+	for _, officer := range new {
+		o, ok := oldMap[officer.FullName]
+		if !ok {
+			changes = append(changes, Change{
+				Officer: officer.FullName,
+				New:     officer.FullName,
+			})
+			o = &Officer{FullName: officer.FullName}
+		}
+
+		changes = append(changes, diffOfficer(*o, officer)...)
+	}
+
+	return changes
+}
+
+func diffOfficer(old, new Officer) []Change {
+	var changes []Change
+
+	str := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, Change{new.FullName, field, o, n})
+		}
+	}
+
+	str("picture", old.Picture, new.Picture)
+	str("socials.website", old.Socials.Website, new.Socials.Website)
+	str("socials.github", old.Socials.GitHub, new.Socials.GitHub)
+	str("socials.discord", old.Socials.Discord, new.Socials.Discord)
+	str("socials.linkedin", old.Socials.LinkedIn, new.Socials.LinkedIn)
+	str("socials.instagram", old.Socials.Instagram, new.Socials.Instagram)
+	str("socials.discordId", old.Socials.DiscordID, new.Socials.DiscordID)
+
+	extra := func(prefix string, o, n Extra) {
+		keys := o.Keys()
+		for _, key := range n.Keys() {
+			if _, ok := o[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			oldValue, _ := o.String(key)
+			newValue, _ := n.String(key)
+			str(prefix+key, oldValue, newValue)
+		}
+	}
+
+	extra("socials.", old.Socials.Extra, new.Socials.Extra)
+	extra("", old.Extra, new.Extra)
+
+	terms := make([]Term, 0, len(old.Terms)+len(new.Terms))
+	for term := range old.Terms {
+		terms = append(terms, term)
+	}
+	for term := range new.Terms {
+		if _, ok := old.Terms[term]; !ok {
+			terms = append(terms, term)
+		}
+	}
+	SortTerms(terms)
+
+	for _, term := range terms {
+		var o, n string
+		if t, ok := old.Terms[term]; ok {
+			o = t.String()
+		}
+		if t, ok := new.Terms[term]; ok {
+			n = t.String()
+		}
+		str("terms."+string(term), o, n)
+	}
+
+	return changes
+}
@@ -0,0 +1,132 @@
+package acmcsuf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffOfficers(t *testing.T) {
+	ada := Officer{
+		FullName: "Ada",
+		Picture:  "ada.jpg",
+		Socials:  Socials{GitHub: "ada", DiscordID: "1"},
+		Terms:    map[Term]OfficerTerm{"F22": {Title: "President", Tier: 0}},
+	}
+
+	// with returns a copy of ada changed by f.
+	with := func(f func(o *Officer)) Officer {
+		o := ada
+		o.Terms = make(map[Term]OfficerTerm, len(ada.Terms))
+		for term, officerTerm := range ada.Terms {
+			o.Terms[term] = officerTerm
+		}
+		f(&o)
+		return o
+	}
+
+	tests := []struct {
+		name     string
+		old, new Officers
+		want     []Change
+		strings  []string
+	}{
+		{
+			name: "no changes",
+			old:  Officers{ada},
+			new:  Officers{with(func(o *Officer) {})},
+		},
+		{
+			name: "added officer",
+			old:  Officers{ada},
+			new:  Officers{ada, {FullName: "Bob", Socials: Socials{GitHub: "bob"}}},
+			want: []Change{
+				{Officer: "Bob", New: "Bob"},
+				{Officer: "Bob", Field: "socials.github", New: "bob"},
+			},
+			strings: []string{
+				"add officer Bob",
+				`Bob: set socials.github to "bob"`,
+			},
+		},
+		{
+			name: "removed officer",
+			old:  Officers{ada, {FullName: "Bob", Socials: Socials{GitHub: "bob"}}},
+			new:  Officers{ada},
+			want: []Change{
+				{Officer: "Bob", Old: "Bob"},
+			},
+			strings: []string{"remove officer Bob"},
+		},
+		{
+			name: "changed field",
+			old:  Officers{ada},
+			new:  Officers{with(func(o *Officer) { o.Picture = "ada.webp" })},
+			want: []Change{
+				{Officer: "Ada", Field: "picture", Old: "ada.jpg", New: "ada.webp"},
+			},
+			strings: []string{`Ada: change picture from "ada.jpg" to "ada.webp"`},
+		},
+		{
+			name: "changed socials",
+			old:  Officers{ada},
+			new: Officers{with(func(o *Officer) {
+				o.Socials.GitHub = ""
+				o.Socials.Instagram = "ada_l"
+				o.Socials.DiscordID = "2"
+			})},
+			want: []Change{
+				{Officer: "Ada", Field: "socials.github", Old: "ada"},
+				{Officer: "Ada", Field: "socials.instagram", New: "ada_l"},
+				{Officer: "Ada", Field: "socials.discordId", Old: "1", New: "2"},
+			},
+			strings: []string{
+				`Ada: unset socials.github (was "ada")`,
+				`Ada: set socials.instagram to "ada_l"`,
+				`Ada: change socials.discordId from "1" to "2"`,
+			},
+		},
+		{
+			name: "added term",
+			old:  Officers{ada},
+			new: Officers{with(func(o *Officer) {
+				o.Terms["S23"] = OfficerTerm{Title: "Advisor", Tier: 1}
+			})},
+			want: []Change{
+				{Officer: "Ada", Field: "terms.S23", New: "Advisor (tier 1)"},
+			},
+			strings: []string{`Ada: set terms.S23 to "Advisor (tier 1)"`},
+		},
+		{
+			name: "removed and changed terms",
+			old: Officers{with(func(o *Officer) {
+				o.Terms["S22"] = OfficerTerm{Title: "Advisor", Tier: 1}
+			})},
+			new: Officers{with(func(o *Officer) {
+				o.Terms["F22"] = OfficerTerm{Title: "Advisor", Tier: 1}
+			})},
+			want: []Change{
+				{Officer: "Ada", Field: "terms.S22", Old: "Advisor (tier 1)"},
+				{Officer: "Ada", Field: "terms.F22", Old: "President (tier 0)", New: "Advisor (tier 1)"},
+			},
+			strings: []string{
+				`Ada: unset terms.S22 (was "Advisor (tier 1)")`,
+				`Ada: change terms.F22 from "President (tier 0)" to "Advisor (tier 1)"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := DiffOfficers(test.old, test.new)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("DiffOfficers = %#v, want %#v", got, test.want)
+			}
+
+			for i, change := range got {
+				if s := change.String(); s != test.strings[i] {
+					t.Errorf("change %d = %q, want %q", i, s, test.strings[i])
+				}
+			}
+		})
+	}
+}
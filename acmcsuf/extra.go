@@ -0,0 +1,160 @@
+package acmcsuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// Extra holds the JSON fields of an object that this package doesn't know
+// about, such as ones newly added by the website. They are kept when decoding
+// and written back when encoding, so no data is lost.
+type Extra map[string]json.RawMessage
+
+// Keys returns the sorted keys of the extra fields.
+func (e Extra) Keys() []string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the raw JSON value of the extra field with the given key.
+func (e Extra) Get(key string) (json.RawMessage, bool) {
+	v, ok := e[key]
+	return v, ok
+}
+
+// String returns the extra field with the given key as a string. If the value
+// is not a JSON string, then its raw JSON is returned.
+func (e Extra) String(key string) (string, bool) {
+	v, ok := e[key]
+	if !ok {
+		return "", false
+	}
+
+	var s string
+	if err := json.Unmarshal(v, &s); err != nil {
+		return string(v), true
+	}
+
+	return s, true
+}
+
+// Set sets the extra field with the given key to the raw JSON value.
+func (e *Extra) Set(key string, value json.RawMessage) {
+	if *e == nil {
+		*e = make(Extra)
+	}
+	(*e)[key] = value
+}
+
+// SetString sets the extra field with the given key to a JSON string.
+func (e *Extra) SetString(key, value string) {
+	b, _ := json.Marshal(value)
+	e.Set(key, b)
+}
+
+// Equal returns true if both have the same fields with the same values. Values
+// are compared as JSON, so formatting and the order of object keys don't
+// matter.
+func (e Extra) Equal(other Extra) bool {
+	if len(e) != len(other) {
+		return false
+	}
+	for key, value := range e {
+		otherValue, ok := other[key]
+		if !ok || !sameJSON(value, otherValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameJSON returns true if a and b encode the same JSON value.
+func sameJSON(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	var aValue, bValue interface{}
+
+	aDecoder := json.NewDecoder(bytes.NewReader(a))
+	aDecoder.UseNumber()
+	if err := aDecoder.Decode(&aValue); err != nil {
+		return false
+	}
+
+	bDecoder := json.NewDecoder(bytes.NewReader(b))
+	bDecoder.UseNumber()
+	if err := bDecoder.Decode(&bValue); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+// Delete removes the extra field with the given key.
+func (e *Extra) Delete(key string) {
+	delete(*e, key)
+	if len(*e) == 0 {
+		*e = nil
+	}
+}
+
+var extraType = reflect.TypeOf(Extra(nil))
+
+// IsKnownField returns true if the given key is a field that the Go type of v
+// knows about, meaning that it cannot be used as an extra field.
+func IsKnownField(v interface{}, key string) bool {
+	_, ok := jsonStructField(reflect.Indirect(reflect.ValueOf(v)).Type(), key)
+	return ok
+}
+
+// unmarshalExtra returns the fields in the JSON object that are unknown to the
+// struct type t.
+func unmarshalExtra(b []byte, t reflect.Type) (Extra, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	var extra Extra
+	for key, value := range fields {
+		if _, ok := jsonStructField(t, key); !ok {
+			extra.Set(key, value)
+		}
+	}
+
+	return extra, nil
+}
+
+// marshalExtra appends the extra fields to the encoded JSON object.
+func marshalExtra(b []byte, extra Extra) ([]byte, error) {
+	if len(extra) == 0 {
+		return b, nil
+	}
+
+	b = bytes.TrimRight(b, " \n")
+	b = b[:len(b)-1] // }
+
+	for i, key := range extra.Keys() {
+		if i > 0 || !bytes.HasSuffix(b, []byte("{")) {
+			b = append(b, ',')
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		b = append(b, k...)
+		b = append(b, ':')
+		b = append(b, extra[key]...)
+	}
+
+	return append(b, '}'), nil
+}
@@ -0,0 +1,582 @@
+package acmcsuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// JSONFile remembers the formatting of a JSON file, so that it can be written
+// back with only the changed values differing. This keeps diffs of the data
+// files small and reviewable.
+//
+// The indentation, key order and trailing newline of the original file are
+// kept. Values that didn't change keep their original spelling, and objects
+// that were written on one line stay on one line. Object keys that the Go type
+// doesn't know about are kept as-is.
+type JSONFile struct {
+	root            jsonValue
+	indent          string
+	trailingNewline bool
+}
+
+// DecodeJSONFile decodes the given JSON data into v and remembers its
+// formatting.
+func DecodeJSONFile(data []byte, v interface{}) (*JSONFile, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	root, err := parseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONFile{
+		root:            root,
+		indent:          detectIndent(data),
+		trailingNewline: bytes.HasSuffix(data, []byte("\n")),
+	}, nil
+}
+
+// Marshal encodes v in the format of the original file.
+func (f *JSONFile) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	root, err := parseJSON(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse encoded JSON")
+	}
+
+	merged := mergeJSON(f.root, root, reflect.TypeOf(v))
+
+	var out bytes.Buffer
+	w := jsonWriter{buf: &out, indent: f.indent}
+	w.write(merged, 0)
+
+	if f.trailingNewline {
+		out.WriteByte('\n')
+	}
+
+	return out.Bytes(), nil
+}
+
+// detectIndent returns the indentation used for the first indented line, or
+// an empty string if the file is compact.
+func detectIndent(data []byte) string {
+	lines := bytes.Split(data, []byte("\n"))
+	for _, line := range lines[1:] {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) < len(line) && len(trimmed) > 0 {
+			return string(line[:len(line)-len(trimmed)])
+		}
+	}
+	return ""
+}
+
+// jsonValue is a parsed JSON value. It is either a *jsonObject, a *jsonArray
+// or a jsonScalar.
+type jsonValue interface{}
+
+type jsonObject struct {
+	members   []jsonMember
+	multiline bool
+}
+
+type jsonMember struct {
+	key   string
+	value jsonValue
+}
+
+type jsonArray struct {
+	elems     []jsonValue
+	multiline bool
+}
+
+// jsonScalar is the raw bytes of a string, number, boolean or null.
+type jsonScalar []byte
+
+func parseJSON(data []byte) (jsonValue, error) {
+	if !json.Valid(data) {
+		return nil, errors.New("invalid JSON")
+	}
+
+	p := jsonParser{data: data}
+	return p.value(), nil
+}
+
+// jsonParser parses JSON that is known to be valid.
+type jsonParser struct {
+	data []byte
+	pos  int
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.pos < len(p.data) && isJSONSpace(p.data[p.pos]) {
+		p.pos++
+	}
+}
+
+func isJSONSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+func (p *jsonParser) value() jsonValue {
+	p.skipSpace()
+
+	switch p.data[p.pos] {
+	case '{':
+		return p.object()
+	case '[':
+		return p.array()
+	case '"':
+		return jsonScalar(p.str())
+	default:
+		start := p.pos
+		for p.pos < len(p.data) && !strings.ContainsRune(",]} \t\n\r", rune(p.data[p.pos])) {
+			p.pos++
+		}
+		return jsonScalar(p.data[start:p.pos])
+	}
+}
+
+func (p *jsonParser) str() []byte {
+	start := p.pos
+	p.pos++ // opening quote
+	for p.data[p.pos] != '"' {
+		if p.data[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	p.pos++ // closing quote
+	return p.data[start:p.pos]
+}
+
+func (p *jsonParser) object() *jsonObject {
+	start := p.pos
+	p.pos++ // {
+
+	obj := &jsonObject{}
+	for {
+		p.skipSpace()
+		if p.data[p.pos] == '}' {
+			p.pos++
+			break
+		}
+		if p.data[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+
+		var key string
+		json.Unmarshal(p.str(), &key)
+
+		p.skipSpace()
+		p.pos++ // :
+
+		obj.members = append(obj.members, jsonMember{key, p.value()})
+	}
+
+	obj.multiline = bytes.ContainsRune(p.data[start:p.pos], '\n')
+	return obj
+}
+
+func (p *jsonParser) array() *jsonArray {
+	start := p.pos
+	p.pos++ // [
+
+	arr := &jsonArray{}
+	for {
+		p.skipSpace()
+		if p.data[p.pos] == ']' {
+			p.pos++
+			break
+		}
+		if p.data[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+
+		arr.elems = append(arr.elems, p.value())
+	}
+
+	arr.multiline = bytes.ContainsRune(p.data[start:p.pos], '\n')
+	return arr
+}
+
+// mergeJSON returns newValue formatted like oldValue as much as possible. t is
+// the Go type that newValue was encoded from, which may be nil if unknown.
+func mergeJSON(oldValue, newValue jsonValue, t reflect.Type) jsonValue {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch newValue := newValue.(type) {
+	case *jsonObject:
+		oldObject, ok := oldValue.(*jsonObject)
+		if !ok {
+			return expandJSON(newValue)
+		}
+
+		merged := &jsonObject{
+			multiline: oldObject.multiline || len(oldObject.members) == 0,
+		}
+		isStruct := t != nil && t.Kind() == reflect.Struct
+
+		newKeys := make(map[string]jsonValue, len(newValue.members))
+		for _, member := range newValue.members {
+			newKeys[member.key] = member.value
+		}
+
+		// usedKeys is the set of new keys that are already in merged.
+		usedKeys := make(map[string]struct{}, len(newValue.members))
+		for _, member := range oldObject.members {
+			newKey := member.key
+			if _, ok := newKeys[newKey]; !ok && isStruct {
+				// encoding/json matches struct fields case-insensitively,
+				// so keep the original spelling of the key.
+				for _, m := range newValue.members {
+					if strings.EqualFold(m.key, member.key) {
+						newKey = m.key
+						break
+					}
+				}
+			}
+
+			if value, ok := newKeys[newKey]; ok {
+				usedKeys[newKey] = struct{}{}
+				merged.members = append(merged.members, jsonMember{
+					key:   member.key,
+					value: mergeJSON(member.value, value, jsonFieldType(t, newKey)),
+				})
+				continue
+			}
+
+			if !jsonKnowsField(t, member.key) {
+				// The Go type doesn't know about this key, so it couldn't
+				// have been removed on purpose.
+				merged.members = append(merged.members, member)
+			}
+		}
+
+		for _, member := range newValue.members {
+			if _, ok := usedKeys[member.key]; ok {
+				continue
+			}
+
+			// Go always encodes every struct field. Don't add empty ones
+			// that the original file left out.
+			if isStruct && isEmptyJSON(member.value) {
+				continue
+			}
+
+			merged.members = append(merged.members, jsonMember{
+				key:   member.key,
+				value: expandJSON(member.value),
+			})
+		}
+
+		return merged
+
+	case *jsonArray:
+		oldArray, ok := oldValue.(*jsonArray)
+		if !ok {
+			return expandJSON(newValue)
+		}
+
+		var elemType reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elemType = t.Elem()
+		}
+
+		merged := &jsonArray{
+			elems:     make([]jsonValue, len(newValue.elems)),
+			multiline: oldArray.multiline || len(oldArray.elems) == 0,
+		}
+
+		// Elements with an identity are matched by it, so that adding,
+		// removing or moving an officer doesn't give everyone after it the
+		// formatting of their neighbour. Other elements are matched by their
+		// position.
+		oldIndices := make(map[string]int, len(oldArray.elems))
+		for i, elem := range oldArray.elems {
+			if id, ok := jsonIdentity(elem); ok {
+				if _, dup := oldIndices[id]; !dup {
+					oldIndices[id] = i
+				}
+			}
+		}
+
+		used := make([]bool, len(oldArray.elems))
+		for i, elem := range newValue.elems {
+			j := -1
+			if id, ok := jsonIdentity(elem); ok {
+				if k, ok := oldIndices[id]; ok && !used[k] {
+					j = k
+				}
+			} else if i < len(oldArray.elems) {
+				if _, ok := jsonIdentity(oldArray.elems[i]); !ok && !used[i] {
+					j = i
+				}
+			}
+
+			if j == -1 {
+				merged.elems[i] = expandJSON(elem)
+				continue
+			}
+
+			used[j] = true
+			merged.elems[i] = mergeJSON(oldArray.elems[j], elem, elemType)
+		}
+
+		return merged
+
+	case jsonScalar:
+		oldScalar, ok := oldValue.(jsonScalar)
+		if ok && sameJSONScalar(oldScalar, newValue) {
+			return oldScalar
+		}
+		return newValue
+
+	default:
+		return newValue
+	}
+}
+
+// jsonIdentityKey is the object key that identifies an element of an array
+// across edits.
+const jsonIdentityKey = "fullName"
+
+// jsonIdentity returns the identity of an array element, which is its
+// jsonIdentityKey string if it's an object that has one.
+func jsonIdentity(v jsonValue) (string, bool) {
+	obj, ok := v.(*jsonObject)
+	if !ok {
+		return "", false
+	}
+
+	for _, member := range obj.members {
+		if member.key != jsonIdentityKey {
+			continue
+		}
+		scalar, ok := member.value.(jsonScalar)
+		if !ok {
+			return "", false
+		}
+		var id string
+		if err := json.Unmarshal(scalar, &id); err != nil {
+			return "", false
+		}
+		return id, true
+	}
+
+	return "", false
+}
+
+// expandJSON marks the value and everything in it as multiline. It is used for
+// new values that have no original formatting to follow.
+func expandJSON(v jsonValue) jsonValue {
+	switch v := v.(type) {
+	case *jsonObject:
+		v.multiline = true
+		for _, member := range v.members {
+			expandJSON(member.value)
+		}
+	case *jsonArray:
+		v.multiline = true
+		for _, elem := range v.elems {
+			expandJSON(elem)
+		}
+	}
+	return v
+}
+
+// isEmptyJSON returns true if v is the zero value of some Go type.
+func isEmptyJSON(v jsonValue) bool {
+	switch v := v.(type) {
+	case *jsonObject:
+		return len(v.members) == 0
+	case *jsonArray:
+		return len(v.elems) == 0
+	case jsonScalar:
+		switch string(v) {
+		case `""`, "null", "0", "false":
+			return true
+		}
+	}
+	return false
+}
+
+func sameJSONScalar(a, b jsonScalar) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(av, bv)
+}
+
+// jsonFieldType returns the Go type of the value at the given key of t.
+func jsonFieldType(t reflect.Type, key string) reflect.Type {
+	if t == nil {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem()
+	case reflect.Struct:
+		if field, ok := jsonStructField(t, key); ok {
+			return field.Type
+		}
+	}
+
+	return nil
+}
+
+// jsonKnowsField returns true if the given key can be produced by encoding t.
+// Keys of maps, unknown types and types that keep their unknown fields in an
+// Extra field are always known.
+func jsonKnowsField(t reflect.Type, key string) bool {
+	if t == nil || t.Kind() != reflect.Struct {
+		return true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type == extraType {
+			return true
+		}
+	}
+
+	_, ok := jsonStructField(t, key)
+	return ok
+}
+
+func jsonStructField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+type jsonWriter struct {
+	buf    *bytes.Buffer
+	indent string
+}
+
+func (w jsonWriter) newline(depth int) {
+	w.buf.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		w.buf.WriteString(w.indent)
+	}
+}
+
+func (w jsonWriter) write(v jsonValue, depth int) {
+	switch v := v.(type) {
+	case *jsonObject:
+		if len(v.members) == 0 {
+			w.buf.WriteString("{}")
+			return
+		}
+
+		multiline := v.multiline && w.indent != ""
+
+		w.buf.WriteByte('{')
+		if !multiline && w.indent != "" {
+			w.buf.WriteByte(' ')
+		}
+
+		for i, member := range v.members {
+			if i > 0 {
+				w.buf.WriteByte(',')
+				if !multiline && w.indent != "" {
+					w.buf.WriteByte(' ')
+				}
+			}
+			if multiline {
+				w.newline(depth + 1)
+			}
+
+			key, _ := json.Marshal(member.key)
+			w.buf.Write(key)
+			w.buf.WriteByte(':')
+			if w.indent != "" {
+				w.buf.WriteByte(' ')
+			}
+
+			w.write(member.value, depth+1)
+		}
+
+		if multiline {
+			w.newline(depth)
+		} else if w.indent != "" {
+			w.buf.WriteByte(' ')
+		}
+		w.buf.WriteByte('}')
+
+	case *jsonArray:
+		if len(v.elems) == 0 {
+			w.buf.WriteString("[]")
+			return
+		}
+
+		multiline := v.multiline && w.indent != ""
+
+		w.buf.WriteByte('[')
+		for i, elem := range v.elems {
+			if i > 0 {
+				w.buf.WriteByte(',')
+				if !multiline && w.indent != "" {
+					w.buf.WriteByte(' ')
+				}
+			}
+			if multiline {
+				w.newline(depth + 1)
+			}
+
+			w.write(elem, depth+1)
+		}
+
+		if multiline {
+			w.newline(depth)
+		}
+		w.buf.WriteByte(']')
+
+	case jsonScalar:
+		w.buf.Write(v)
+
+	default:
+		panic(fmt.Sprintf("unknown JSON value %T", v))
+	}
+}
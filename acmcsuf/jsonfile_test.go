@@ -0,0 +1,231 @@
+package acmcsuf
+
+import (
+	"testing"
+)
+
+func TestJSONFile(t *testing.T) {
+	const officers = `[
+  {
+    "fullName": "Ada",
+    "picture": "ada.jpg",
+    "socials": { "github": "ada" },
+    "terms": { "F22": { "title": "President", "tier": 0 } },
+    "bio": "Likes engines."
+  },
+  {
+    "fullName": "Bob",
+    "socials": {
+      "linkedin": "bob"
+    },
+    "picture": "",
+    "terms": {}
+  }
+]
+`
+
+	tests := []struct {
+		name   string
+		data   string
+		update func(officers *Officers)
+		want   string
+	}{
+		{
+			name:   "unchanged",
+			data:   officers,
+			update: func(officers *Officers) {},
+			want:   officers,
+		},
+		{
+			name: "changed value",
+			data: officers,
+			update: func(officers *Officers) {
+				(*officers)[1].Socials.LinkedIn = "bobby"
+			},
+			want: `[
+  {
+    "fullName": "Ada",
+    "picture": "ada.jpg",
+    "socials": { "github": "ada" },
+    "terms": { "F22": { "title": "President", "tier": 0 } },
+    "bio": "Likes engines."
+  },
+  {
+    "fullName": "Bob",
+    "socials": {
+      "linkedin": "bobby"
+    },
+    "picture": "",
+    "terms": {}
+  }
+]
+`,
+		},
+		{
+			name: "added key",
+			data: officers,
+			update: func(officers *Officers) {
+				(*officers)[0].Socials.Instagram = "ada.l"
+			},
+			want: `[
+  {
+    "fullName": "Ada",
+    "picture": "ada.jpg",
+    "socials": { "github": "ada", "instagram": "ada.l" },
+    "terms": { "F22": { "title": "President", "tier": 0 } },
+    "bio": "Likes engines."
+  },
+  {
+    "fullName": "Bob",
+    "socials": {
+      "linkedin": "bob"
+    },
+    "picture": "",
+    "terms": {}
+  }
+]
+`,
+		},
+		{
+			name: "cleared key",
+			data: officers,
+			update: func(officers *Officers) {
+				(*officers)[0].Socials.GitHub = ""
+			},
+			want: `[
+  {
+    "fullName": "Ada",
+    "picture": "ada.jpg",
+    "socials": {},
+    "terms": { "F22": { "title": "President", "tier": 0 } },
+    "bio": "Likes engines."
+  },
+  {
+    "fullName": "Bob",
+    "socials": {
+      "linkedin": "bob"
+    },
+    "picture": "",
+    "terms": {}
+  }
+]
+`,
+		},
+		{
+			name: "removed first element",
+			data: officers,
+			update: func(officers *Officers) {
+				*officers = (*officers)[1:]
+			},
+			want: `[
+  {
+    "fullName": "Bob",
+    "socials": {
+      "linkedin": "bob"
+    },
+    "picture": "",
+    "terms": {}
+  }
+]
+`,
+		},
+		{
+			name: "moved elements",
+			data: officers,
+			update: func(officers *Officers) {
+				o := *officers
+				o[0], o[1] = o[1], o[0]
+			},
+			want: `[
+  {
+    "fullName": "Bob",
+    "socials": {
+      "linkedin": "bob"
+    },
+    "picture": "",
+    "terms": {}
+  },
+  {
+    "fullName": "Ada",
+    "picture": "ada.jpg",
+    "socials": { "github": "ada" },
+    "terms": { "F22": { "title": "President", "tier": 0 } },
+    "bio": "Likes engines."
+  }
+]
+`,
+		},
+		{
+			name: "inserted element",
+			data: officers,
+			update: func(officers *Officers) {
+				*officers = append(Officers{{
+					FullName: "Cat",
+					Socials:  Socials{GitHub: "cat"},
+				}}, *officers...)
+			},
+			want: `[
+  {
+    "fullName": "Cat",
+    "picture": "",
+    "socials": {
+      "github": "cat"
+    },
+    "terms": null
+  },
+  {
+    "fullName": "Ada",
+    "picture": "ada.jpg",
+    "socials": { "github": "ada" },
+    "terms": { "F22": { "title": "President", "tier": 0 } },
+    "bio": "Likes engines."
+  },
+  {
+    "fullName": "Bob",
+    "socials": {
+      "linkedin": "bob"
+    },
+    "picture": "",
+    "terms": {}
+  }
+]
+`,
+		},
+		{
+			name: "tabs without a trailing newline",
+			data: "[\n\t{\n\t\t\"fullName\": \"Ada\",\n\t\t\"picture\": \"\",\n\t\t\"socials\": {}\n\t}\n]",
+			update: func(officers *Officers) {
+				(*officers)[0].Picture = "ada.jpg"
+			},
+			want: "[\n\t{\n\t\t\"fullName\": \"Ada\",\n\t\t\"picture\": \"ada.jpg\",\n\t\t\"socials\": {}\n\t}\n]",
+		},
+		{
+			name: "compact",
+			data: `[{"fullName":"Ada","picture":"","socials":{}}]`,
+			update: func(officers *Officers) {
+				(*officers)[0].Picture = "ada.jpg"
+			},
+			want: `[{"fullName":"Ada","picture":"ada.jpg","socials":{}}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var officers Officers
+			f, err := DecodeJSONFile([]byte(test.data), &officers)
+			if err != nil {
+				t.Fatal("DecodeJSONFile:", err)
+			}
+
+			test.update(&officers)
+
+			got, err := f.Marshal(officers)
+			if err != nil {
+				t.Fatal("Marshal:", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("Marshal =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package acmcsuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MergeConflict describes a field that was changed differently by both sides
+// of a merge.
+type MergeConflict struct {
+	// Officer is the full name of the officer.
+	Officer string
+	// Field is the path to the conflicting field, e.g. "socials.github". It
+	// is empty if the whole officer conflicts, such as when one side removes
+	// the officer while the other modifies it.
+	Field string
+	// Reason explains the conflict if it's not a field changed by both sides.
+	Reason string
+}
+
+// String formats the conflict.
+func (c MergeConflict) String() string {
+	s := c.Officer
+	if c.Field != "" {
+		s += ": " + c.Field
+	}
+	if c.Reason != "" {
+		s += " (" + c.Reason + ")"
+	}
+	return s
+}
+
+// MergeError is returned by MergeOfficers if there are conflicts.
+type MergeError []MergeConflict
+
+// Error implements error.
+func (e MergeError) Error() string {
+	conflicts := make([]string, len(e))
+	for i, c := range e {
+		conflicts[i] = c.String()
+	}
+	return "merge conflicts in " + strings.Join(conflicts, ", ")
+}
+
+// MergeOfficers does a three-way merge of officers. Officers are matched by
+// their full names, and changes made by both ours and theirs are merged field
+// by field. Only fields that were changed differently on both sides are
+// reported as conflicts, in which case a MergeError is returned.
+//
+// The order of theirs is kept, and officers only added in ours are appended.
+// Since officers are matched by name, officers that share a full name on any
+// side are reported as conflicts rather than merged.
+func MergeOfficers(base, ours, theirs Officers) (Officers, error) {
+	var conflicts MergeError
+
+	for _, side := range []struct {
+		name     string
+		officers Officers
+	}{
+		{"base", base},
+		{"ours", ours},
+		{"theirs", theirs},
+	} {
+		for _, name := range duplicateNames(side.officers) {
+			conflicts = append(conflicts, MergeConflict{
+				Officer: name,
+				Reason:  "duplicate full name in " + side.name,
+			})
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+
+	baseMap := officersByName(base)
+	oursMap := officersByName(ours)
+	theirsMap := officersByName(theirs)
+
+	names := make([]string, 0, len(theirs)+len(ours))
+	for _, officer := range theirs {
+		names = append(names, officer.FullName)
+	}
+	for _, officer := range ours {
+		if _, ok := theirsMap[officer.FullName]; !ok {
+			names = append(names, officer.FullName)
+		}
+	}
+
+	var merged Officers
+
+	for _, name := range names {
+		b, o, t := baseMap[name], oursMap[name], theirsMap[name]
+
+		switch {
+		case sameOfficer(o, t), sameOfficer(o, b):
+			// Either both sides agree or only theirs changed.
+			if t != nil {
+				merged = append(merged, *t)
+			}
+		case sameOfficer(t, b):
+			// Only ours changed.
+			if o != nil {
+				merged = append(merged, *o)
+			}
+		case o == nil || t == nil:
+			// One side removed the officer while the other changed it.
+			conflicts = append(conflicts, MergeConflict{Officer: name})
+		default:
+			if b == nil {
+				// Both sides added the officer, so merge against an empty
+				// one.
+				b = &Officer{FullName: name}
+			}
+
+			officer, officerConflicts := mergeOfficer(*b, *o, *t)
+			conflicts = append(conflicts, officerConflicts...)
+			merged = append(merged, officer)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, conflicts
+	}
+
+	return merged, nil
+}
+
+// sameOfficer returns true if a and b are the same officer. Extra fields are
+// compared as JSON, so that reformatting officers.json doesn't count as a
+// change. Either may be nil.
+func sameOfficer(a, b *Officer) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.FullName == b.FullName &&
+		a.Picture == b.Picture &&
+		a.Socials.Website == b.Socials.Website &&
+		a.Socials.GitHub == b.Socials.GitHub &&
+		a.Socials.Discord == b.Socials.Discord &&
+		a.Socials.LinkedIn == b.Socials.LinkedIn &&
+		a.Socials.Instagram == b.Socials.Instagram &&
+		a.Socials.DiscordID == b.Socials.DiscordID &&
+		a.Socials.Extra.Equal(b.Socials.Extra) &&
+		sameTerms(a.Terms, b.Terms) &&
+		a.Extra.Equal(b.Extra)
+}
+
+func sameTerms(a, b map[Term]OfficerTerm) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for term, officerTerm := range a {
+		if other, ok := b[term]; !ok || other != officerTerm {
+			return false
+		}
+	}
+	return true
+}
+
+// duplicateNames returns the full names that more than one officer has, in
+// order of their first appearance.
+func duplicateNames(officers Officers) []string {
+	seen := make(map[string]int, len(officers))
+	var dups []string
+	for _, officer := range officers {
+		seen[officer.FullName]++
+		if seen[officer.FullName] == 2 {
+			dups = append(dups, officer.FullName)
+		}
+	}
+	return dups
+}
+
+func officersByName(officers Officers) map[string]*Officer {
+	m := make(map[string]*Officer, len(officers))
+	for i := range officers {
+		m[officers[i].FullName] = &officers[i]
+	}
+	return m
+}
+
+type fieldMerger struct {
+	officer   string
+	conflicts MergeError
+}
+
+func (m *fieldMerger) str(field string, base, ours, theirs string) string {
+	switch {
+	case ours == theirs, ours == base:
+		return theirs
+	case theirs == base:
+		return ours
+	default:
+		m.conflicts = append(m.conflicts, MergeConflict{Officer: m.officer, Field: field})
+		return theirs
+	}
+}
+
+func mergeOfficer(base, ours, theirs Officer) (Officer, MergeError) {
+	m := fieldMerger{officer: theirs.FullName}
+
+	merged := Officer{FullName: theirs.FullName}
+	merged.Picture = m.str("picture", base.Picture, ours.Picture, theirs.Picture)
+
+	merged.Socials = Socials{
+		Website:   m.str("socials.website", base.Socials.Website, ours.Socials.Website, theirs.Socials.Website),
+		GitHub:    m.str("socials.github", base.Socials.GitHub, ours.Socials.GitHub, theirs.Socials.GitHub),
+		Discord:   m.str("socials.discord", base.Socials.Discord, ours.Socials.Discord, theirs.Socials.Discord),
+		LinkedIn:  m.str("socials.linkedin", base.Socials.LinkedIn, ours.Socials.LinkedIn, theirs.Socials.LinkedIn),
+		Instagram: m.str("socials.instagram", base.Socials.Instagram, ours.Socials.Instagram, theirs.Socials.Instagram),
+		DiscordID: m.str("socials.discordId", base.Socials.DiscordID, ours.Socials.DiscordID, theirs.Socials.DiscordID),
+	}
+
+	merged.Socials.Extra = mergeExtra(&m, "socials.", base.Socials.Extra, ours.Socials.Extra, theirs.Socials.Extra)
+
+	merged.Terms = mergeTerms(&m, base.Terms, ours.Terms, theirs.Terms)
+	merged.Extra = mergeExtra(&m, "", base.Extra, ours.Extra, theirs.Extra)
+
+	return merged, m.conflicts
+}
+
+func mergeTerms(m *fieldMerger, base, ours, theirs map[Term]OfficerTerm) map[Term]OfficerTerm {
+	if theirs == nil && ours == nil {
+		return nil
+	}
+
+	merged := make(map[Term]OfficerTerm, len(theirs)+len(ours))
+
+	terms := make(map[Term]struct{}, len(theirs)+len(ours))
+	for term := range theirs {
+		terms[term] = struct{}{}
+	}
+	for term := range ours {
+		terms[term] = struct{}{}
+	}
+
+	for term := range terms {
+		b, bok := base[term]
+		o, ook := ours[term]
+		t, tok := theirs[term]
+
+		switch {
+		case ook == tok && o == t, ook == bok && o == b:
+			if tok {
+				merged[term] = t
+			}
+		case tok == bok && t == b:
+			if ook {
+				merged[term] = o
+			}
+		default:
+			m.conflicts = append(m.conflicts, MergeConflict{Officer: m.officer, Field: fmt.Sprintf("terms.%s", term)})
+			if tok {
+				merged[term] = t
+			}
+		}
+	}
+
+	return merged
+}
+
+func mergeExtra(m *fieldMerger, prefix string, base, ours, theirs Extra) Extra {
+	var merged Extra
+
+	keys := make(map[string]struct{}, len(theirs)+len(ours))
+	for key := range theirs {
+		keys[key] = struct{}{}
+	}
+	for key := range ours {
+		keys[key] = struct{}{}
+	}
+
+	for key := range keys {
+		b, bok := base[key]
+		o, ook := ours[key]
+		t, tok := theirs[key]
+
+		switch {
+		case ook == tok && sameJSON(o, t), ook == bok && sameJSON(o, b):
+			if tok {
+				merged.Set(key, t)
+			}
+		case tok == bok && sameJSON(t, b):
+			if ook {
+				merged.Set(key, o)
+			}
+		default:
+			m.conflicts = append(m.conflicts, MergeConflict{Officer: m.officer, Field: prefix + key})
+			if tok {
+				merged.Set(key, t)
+			}
+		}
+	}
+
+	return merged
+}
+
+// MergeOfficersJSON is like MergeOfficers, except it works on the raw
+// officers.json contents. A nil slice means that the file does not exist on
+// that side. It can be used as a merge driver for OfficersJSONPath.
+func MergeOfficersJSON(base, ours, theirs []byte) ([]byte, error) {
+	if ours == nil || theirs == nil {
+		return nil, errors.New("officers.json was deleted")
+	}
+
+	var baseOfficers, oursOfficers, theirsOfficers Officers
+
+	if base != nil {
+		if err := json.Unmarshal(base, &baseOfficers); err != nil {
+			return nil, errors.Wrap(err, "cannot decode base officers.json")
+		}
+	}
+
+	if err := json.Unmarshal(ours, &oursOfficers); err != nil {
+		return nil, errors.Wrap(err, "cannot decode our officers.json")
+	}
+
+	// Upstream's formatting wins.
+	theirsFile, err := DecodeJSONFile(theirs, &theirsOfficers)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode their officers.json")
+	}
+
+	merged, err := MergeOfficers(baseOfficers, oursOfficers, theirsOfficers)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := theirsFile.Marshal(merged)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot encode merged officers.json")
+	}
+
+	return b, nil
+}
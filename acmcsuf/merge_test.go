@@ -0,0 +1,196 @@
+package acmcsuf
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergeOfficers(t *testing.T) {
+	officer := func(name, github string, terms map[Term]OfficerTerm) Officer {
+		return Officer{
+			FullName: name,
+			Socials:  Socials{GitHub: github},
+			Terms:    terms,
+		}
+	}
+
+	president := map[Term]OfficerTerm{"F22": {Title: "President", Tier: 0}}
+	presidents := map[Term]OfficerTerm{
+		"F22": {Title: "President", Tier: 0},
+		"S23": {Title: "President", Tier: 0},
+	}
+
+	tests := []struct {
+		name      string
+		base      Officers
+		ours      Officers
+		theirs    Officers
+		want      Officers
+		conflicts MergeError
+	}{
+		{
+			name:   "only ours changed",
+			base:   Officers{officer("Ada", "", nil)},
+			ours:   Officers{officer("Ada", "ada", nil)},
+			theirs: Officers{officer("Ada", "", nil)},
+			want:   Officers{officer("Ada", "ada", nil)},
+		},
+		{
+			name:   "only theirs changed",
+			base:   Officers{officer("Ada", "", nil)},
+			ours:   Officers{officer("Ada", "", nil)},
+			theirs: Officers{officer("Ada", "ada", nil)},
+			want:   Officers{officer("Ada", "ada", nil)},
+		},
+		{
+			name:   "different fields of one officer",
+			base:   Officers{officer("Ada", "", nil)},
+			ours:   Officers{officer("Ada", "ada", nil)},
+			theirs: Officers{officer("Ada", "", president)},
+			want:   Officers{officer("Ada", "ada", president)},
+		},
+		{
+			name:   "different officers",
+			base:   Officers{officer("Ada", "", nil), officer("Bob", "", nil)},
+			ours:   Officers{officer("Ada", "ada", nil), officer("Bob", "", nil)},
+			theirs: Officers{officer("Ada", "", nil), officer("Bob", "bob", nil)},
+			want:   Officers{officer("Ada", "ada", nil), officer("Bob", "bob", nil)},
+		},
+		{
+			name:   "both added terms",
+			base:   Officers{officer("Ada", "", nil)},
+			ours:   Officers{officer("Ada", "", president)},
+			theirs: Officers{officer("Ada", "", map[Term]OfficerTerm{"S23": {Title: "President"}})},
+			want:   Officers{officer("Ada", "", presidents)},
+		},
+		{
+			name:   "added on both sides keeps their order",
+			base:   Officers{officer("Ada", "", nil)},
+			ours:   Officers{officer("Ada", "", nil), officer("Cat", "", nil)},
+			theirs: Officers{officer("Bob", "", nil), officer("Ada", "", nil)},
+			want:   Officers{officer("Bob", "", nil), officer("Ada", "", nil), officer("Cat", "", nil)},
+		},
+		{
+			name:      "same field changed differently",
+			base:      Officers{officer("Ada", "", nil)},
+			ours:      Officers{officer("Ada", "ada", nil)},
+			theirs:    Officers{officer("Ada", "ada2", nil)},
+			conflicts: MergeError{{Officer: "Ada", Field: "socials.github"}},
+		},
+		{
+			name:      "removed while changed",
+			base:      Officers{officer("Ada", "", nil)},
+			ours:      Officers{officer("Ada", "ada", nil)},
+			theirs:    Officers{},
+			conflicts: MergeError{{Officer: "Ada"}},
+		},
+		{
+			name:   "duplicate names",
+			base:   Officers{officer("Ada", "", nil)},
+			ours:   Officers{officer("Ada", "ada", nil)},
+			theirs: Officers{officer("Ada", "", nil), officer("Ada", "other", nil)},
+			conflicts: MergeError{
+				{Officer: "Ada", Reason: "duplicate full name in theirs"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := MergeOfficers(test.base, test.ours, test.theirs)
+			if test.conflicts != nil {
+				var mergeErr MergeError
+				if !errors.As(err, &mergeErr) {
+					t.Fatalf("error = %v, want MergeError", err)
+				}
+				if !reflect.DeepEqual(mergeErr, test.conflicts) {
+					t.Fatalf("conflicts = %v, want %v", mergeErr, test.conflicts)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal("MergeOfficers:", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("merged =\n%+v\nwant\n%+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMergeOfficersExtraFormatting(t *testing.T) {
+	withExtra := func(github, bio string) Officer {
+		o := Officer{FullName: "Ada", Socials: Socials{GitHub: github}}
+		o.Extra.Set("bio", json.RawMessage(bio))
+		return o
+	}
+
+	// The website's formatter reordered and reindented the extra field, which
+	// is not a change.
+	base := Officers{withExtra("", `{"a": 1, "b": [1, 2]}`)}
+	ours := Officers{withExtra("ada", `{"a": 1, "b": [1, 2]}`)}
+	theirs := Officers{withExtra("", `{"b":[1,2],"a":1}`)}
+
+	got, err := MergeOfficers(base, ours, theirs)
+	if err != nil {
+		t.Fatal("MergeOfficers:", err)
+	}
+
+	if got[0].Socials.GitHub != "ada" {
+		t.Errorf("github = %q, want ada", got[0].Socials.GitHub)
+	}
+	if bio, _ := got[0].Extra.Get("bio"); !sameJSON(bio, json.RawMessage(`{"a":1,"b":[1,2]}`)) {
+		t.Errorf("bio = %s, want it unchanged", bio)
+	}
+}
+
+func TestMergeOfficersJSON(t *testing.T) {
+	base := []byte(`[
+  {
+    "fullName": "Ada",
+    "picture": "",
+    "socials": { "github": "" },
+    "terms": {}
+  }
+]
+`)
+	ours := []byte(`[{"fullName":"Ada","picture":"","socials":{"github":"ada"},"terms":{}}]`)
+	theirs := []byte(`[
+  {
+    "fullName": "Ada",
+    "picture": "ada.jpg",
+    "socials": { "github": "" },
+    "terms": {}
+  }
+]
+`)
+	want := `[
+  {
+    "fullName": "Ada",
+    "picture": "ada.jpg",
+    "socials": { "github": "ada" },
+    "terms": {}
+  }
+]
+`
+
+	got, err := MergeOfficersJSON(base, ours, theirs)
+	if err != nil {
+		t.Fatal("MergeOfficersJSON:", err)
+	}
+	if string(got) != want {
+		t.Errorf("merged =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := MergeOfficersJSON(base, nil, theirs); err == nil {
+		t.Error("MergeOfficersJSON with a deleted file succeeded")
+	}
+
+	conflicting := []byte(`[{"fullName":"Ada","picture":"","socials":{"github":"ada2"},"terms":{}}]`)
+	if _, err := MergeOfficersJSON(base, ours, conflicting); err == nil {
+		t.Error("MergeOfficersJSON with conflicting changes succeeded")
+	}
+}
@@ -0,0 +1,166 @@
+package acmcsuf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"path"
+	"strings"
+	"unicode"
+
+	// Register the formats that pictures may be uploaded in.
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/pkg/errors"
+)
+
+// PicturesPath is the directory in the acmcsuf.com repository that officer
+// pictures are stored in. Officer.Picture is the name of a file in it.
+const PicturesPath = "./static/assets/authors"
+
+// Constants for officer pictures.
+const (
+	// PictureSize is the width and height of the pictures that the website
+	// expects.
+	PictureSize = 512
+	// MinPictureSize is the smallest width or height that a picture may have
+	// before it's cropped and scaled. Smaller pictures look blurry.
+	MinPictureSize = 256
+	// MaxPictureSize is the largest width or height that a picture may have.
+	// It bounds the memory used to decode the picture.
+	MaxPictureSize = 2048
+	// MaxPictureBytes is the largest file size of a picture.
+	MaxPictureBytes = 10 << 20
+)
+
+// PictureName returns the file name of the picture of the officer with the
+// given full name, e.g. "diamond-burned.jpg" for "Diamond Burned".
+func PictureName(fullName string) string {
+	var slug strings.Builder
+	for _, r := range foldName(fullName) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			slug.WriteRune(r)
+		case slug.Len() > 0 && !strings.HasSuffix(slug.String(), "-"):
+			slug.WriteByte('-')
+		}
+	}
+
+	name := strings.TrimSuffix(slug.String(), "-")
+	if name == "" {
+		name = "officer"
+	}
+
+	return name + ".jpg"
+}
+
+// PicturePath returns the path of the picture with the given name in the
+// repository.
+func PicturePath(name string) string {
+	return path.Join(PicturesPath, name)
+}
+
+// ProcessPicture reads an uploaded picture and converts it into the format
+// that the website expects: a square JPEG of PictureSize pixels, cropped from
+// the center of the picture.
+func ProcessPicture(r io.Reader) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, MaxPictureBytes+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read picture")
+	}
+	if len(b) > MaxPictureBytes {
+		return nil, fmt.Errorf("picture is larger than %d MB", MaxPictureBytes>>20)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return nil, errors.New("picture must be a JPEG, PNG or GIF image")
+	}
+
+	if config.Width < MinPictureSize || config.Height < MinPictureSize {
+		return nil, fmt.Errorf(
+			"picture is %dx%d, but it must be at least %dx%d",
+			config.Width, config.Height, MinPictureSize, MinPictureSize)
+	}
+	if config.Width > MaxPictureSize || config.Height > MaxPictureSize {
+		return nil, fmt.Errorf(
+			"picture is %dx%d, but it must be at most %dx%d",
+			config.Width, config.Height, MaxPictureSize, MaxPictureSize)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot decode %s picture", format)
+	}
+
+	square := scaleSquare(img, cropSquare(img.Bounds()), PictureSize)
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, square, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, errors.Wrap(err, "cannot encode picture")
+	}
+
+	return out.Bytes(), nil
+}
+
+// cropSquare returns the largest square in the center of the bounds.
+func cropSquare(bounds image.Rectangle) image.Rectangle {
+	size := bounds.Dx()
+	if bounds.Dy() < size {
+		size = bounds.Dy()
+	}
+
+	min := bounds.Min.Add(image.Pt((bounds.Dx()-size)/2, (bounds.Dy()-size)/2))
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(size, size))}
+}
+
+// scaleSquare scales the square rect of src to size by averaging the pixels
+// that each destination pixel covers. Transparent pixels are flattened onto
+// white, since JPEG has no transparency. Only the pixels inside rect are read,
+// and nothing but the result is allocated.
+func scaleSquare(src image.Image, rect image.Rectangle, size int) *image.RGBA {
+	// span returns the source pixels covered by the destination pixel i.
+	n := rect.Dx()
+	span := func(i int) (int, int) {
+		start := i * n / size
+		end := (i + 1) * n / size
+		if end == start {
+			end = start + 1
+		}
+		return start, end
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := span(y)
+		for x := 0; x < size; x++ {
+			x0, x1 := span(x)
+
+			var r, g, b, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// The colors are premultiplied, so adding the
+					// transparent part as white draws them over white.
+					sr, sg, sb, sa := src.At(rect.Min.X+sx, rect.Min.Y+sy).RGBA()
+					r += uint64(sr + 0xFFFF - sa)
+					g += uint64(sg + 0xFFFF - sa)
+					b += uint64(sb + 0xFFFF - sa)
+					count++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / count >> 8),
+				G: uint8(g / count >> 8),
+				B: uint8(b / count >> 8),
+				A: 0xFF,
+			})
+		}
+	}
+
+	return dst
+}
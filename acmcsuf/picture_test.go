@@ -0,0 +1,83 @@
+package acmcsuf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessPicture(t *testing.T) {
+	// The left half is red and the right half is transparent, so the center
+	// square is half red and half white.
+	src := image.NewNRGBA(image.Rect(0, 0, 600, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 300; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: 0xFF, A: 0xFF})
+		}
+	}
+
+	b, err := ProcessPicture(bytes.NewReader(encodePNG(t, src)))
+	if err != nil {
+		t.Fatal("ProcessPicture:", err)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal("output is not a JPEG:", err)
+	}
+	if size := img.Bounds().Size(); size != image.Pt(PictureSize, PictureSize) {
+		t.Fatalf("output is %v, want %dx%d", size, PictureSize, PictureSize)
+	}
+
+	near := func(c color.Color, r, g, b uint32) bool {
+		cr, cg, cb, _ := c.RGBA()
+		diff := func(a, b uint32) bool { return a>>8 > b+16 || a>>8+16 < b }
+		return !diff(cr, r) && !diff(cg, g) && !diff(cb, b)
+	}
+	if c := img.At(PictureSize/4, PictureSize/2); !near(c, 0xFF, 0, 0) {
+		t.Errorf("left of the output is %v, want red", c)
+	}
+	if c := img.At(PictureSize*3/4, PictureSize/2); !near(c, 0xFF, 0xFF, 0xFF) {
+		t.Errorf("right of the output is %v, want white", c)
+	}
+}
+
+func TestProcessPictureSize(t *testing.T) {
+	tests := []struct {
+		name string
+		size image.Point
+		ok   bool
+	}{
+		{"smallest", image.Pt(MinPictureSize, MinPictureSize), true},
+		{"largest", image.Pt(MaxPictureSize, MinPictureSize), true},
+		{"too small", image.Pt(MinPictureSize-1, 1000), false},
+		{"too large", image.Pt(MaxPictureSize+1, MinPictureSize), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img := image.NewGray(image.Rectangle{Max: test.size})
+			_, err := ProcessPicture(bytes.NewReader(encodePNG(t, img)))
+			if (err == nil) != test.ok {
+				t.Errorf("ProcessPicture(%v) error = %v, want ok = %v", test.size, err, test.ok)
+			}
+		})
+	}
+
+	if _, err := ProcessPicture(bytes.NewReader([]byte("not a picture"))); err == nil {
+		t.Error("ProcessPicture of garbage succeeded")
+	}
+}
@@ -0,0 +1,221 @@
+package acmcsuf
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// ErrOfficerNotFound is returned by Officers.FindOne if no officer matches.
+var ErrOfficerNotFound = errors.New("officer not found")
+
+// AmbiguousError is returned by Officers.FindOne if more than one officer
+// matches.
+type AmbiguousError struct {
+	Matches []*Officer
+}
+
+// Error implements error.
+func (err *AmbiguousError) Error() string {
+	names := make([]string, len(err.Matches))
+	for i, officer := range err.Matches {
+		names[i] = officer.FullName
+	}
+	return fmt.Sprintf("ambiguous match for %d officers: %s", len(names), strings.Join(names, ", "))
+}
+
+// Matcher is a predicate that matches officers. Matchers are used with
+// Officers.Find, Officers.FindAll and Officers.FindOne.
+type Matcher func(*Officer) bool
+
+// FindAll returns all officers that match.
+func (o Officers) FindAll(match Matcher) []*Officer {
+	var matches []*Officer
+	for i := range o {
+		if match(&o[i]) {
+			matches = append(matches, &o[i])
+		}
+	}
+	return matches
+}
+
+// FindOne returns the only officer that matches. ErrOfficerNotFound is
+// returned if none do, and an *AmbiguousError is returned if more than one
+// does.
+func (o Officers) FindOne(match Matcher) (*Officer, error) {
+	matches := o.FindAll(match)
+	switch len(matches) {
+	case 0:
+		return nil, ErrOfficerNotFound
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, &AmbiguousError{Matches: matches}
+	}
+}
+
+// ByName matches officers by their full name. The name is matched ignoring
+// case, diacritics and extra whitespace, so "jose  garcia" matches "José
+// García".
+func ByName(name string) Matcher {
+	name = foldName(name)
+	return func(officer *Officer) bool {
+		return foldName(officer.FullName) == name
+	}
+}
+
+// ByDiscordID matches officers by their Discord ID.
+func ByDiscordID(id string) Matcher {
+	return func(officer *Officer) bool {
+		return id != "" && officer.Socials.DiscordID == id
+	}
+}
+
+// ByDiscordUser matches officers that are linked to the given Discord user.
+// Officers that have no Discord ID yet are matched by their handle instead;
+// officers that have one are never matched by handle, since handles can be
+// changed and reused by other users.
+func ByDiscordUser(id, handle string) Matcher {
+	byID := ByDiscordID(id)
+	byHandle := ByDiscord(handle)
+	return func(officer *Officer) bool {
+		if officer.Socials.DiscordID != "" {
+			return byID(officer)
+		}
+		return byHandle(officer)
+	}
+}
+
+// ByDiscord matches officers by their Discord handle, ignoring case. Prefer
+// ByDiscordUser, since handles can change.
+func ByDiscord(tag string) Matcher {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "@")
+	return func(officer *Officer) bool {
+		return tag != "" && strings.EqualFold(officer.Socials.Discord, tag)
+	}
+}
+
+// ByGitHub matches officers by their GitHub handle, ignoring case. A leading
+// "@" is ignored.
+func ByGitHub(handle string) Matcher {
+	handle = strings.TrimPrefix(strings.TrimSpace(handle), "@")
+	return func(officer *Officer) bool {
+		github := strings.TrimPrefix(officer.Socials.GitHub, "@")
+		return handle != "" && strings.EqualFold(github, handle)
+	}
+}
+
+// ActiveIn matches officers that hold a position in the given term.
+func ActiveIn(term Term) Matcher {
+	return func(officer *Officer) bool {
+		_, ok := officer.Terms[term]
+		return ok
+	}
+}
+
+// HoldingTitle matches officers that have held the given title in any term,
+// ignoring case. Combine it with ActiveIn to match a single term.
+func HoldingTitle(title string) Matcher {
+	return func(officer *Officer) bool {
+		for _, term := range officer.Terms {
+			if strings.EqualFold(term.Title, title) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HoldingTitleIn matches officers that held the given title in the given
+// term, ignoring case.
+func HoldingTitleIn(title string, term Term) Matcher {
+	return func(officer *Officer) bool {
+		officerTerm, ok := officer.Terms[term]
+		return ok && strings.EqualFold(officerTerm.Title, title)
+	}
+}
+
+// All matches officers that match all of the given matchers.
+func All(matchers ...Matcher) Matcher {
+	return func(officer *Officer) bool {
+		for _, match := range matchers {
+			if !match(officer) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Any matches officers that match any of the given matchers.
+func Any(matchers ...Matcher) Matcher {
+	return func(officer *Officer) bool {
+		for _, match := range matchers {
+			if match(officer) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// foldName folds the name for comparison: it is lowercased, stripped of
+// diacritics and its whitespace is collapsed.
+func foldName(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+
+	for _, word := range strings.Fields(name) {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		for _, r := range word {
+			if unicode.Is(unicode.Mn, r) {
+				// Combining marks from decomposed diacritics.
+				continue
+			}
+			r = unicode.ToLower(r)
+			if base, ok := diacritics[r]; ok {
+				r = base
+			}
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// diacritics maps lowercase precomposed Latin letters to their base letters.
+var diacritics = func() map[rune]rune {
+	table := map[rune]string{
+		'a': "àáâãäåāăą",
+		'c': "çćĉċč",
+		'd': "ďđ",
+		'e': "èéêëēĕėęě",
+		'g': "ĝğġģ",
+		'h': "ĥħ",
+		'i': "ìíîïĩīĭįı",
+		'j': "ĵ",
+		'k': "ķ",
+		'l': "ĺļľŀł",
+		'n': "ñńņňŉ",
+		'o': "òóôõöøōŏő",
+		'r': "ŕŗř",
+		's': "śŝşšș",
+		't': "ţťŧț",
+		'u': "ùúûüũūŭůűų",
+		'w': "ŵ",
+		'y': "ýÿŷ",
+		'z': "źżž",
+	}
+
+	diacritics := make(map[rune]rune)
+	for base, runes := range table {
+		for _, r := range runes {
+			diacritics[r] = base
+		}
+	}
+	return diacritics
+}()
@@ -0,0 +1,105 @@
+package acmcsuf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatchers(t *testing.T) {
+	officers := Officers{
+		{
+			FullName: "José García",
+			Socials:  Socials{GitHub: "@jgarcia", Discord: "Jose", DiscordID: "1"},
+			Terms: map[Term]OfficerTerm{
+				"F22": {Title: "President"},
+				"S23": {Title: "Advisor"},
+			},
+		},
+		{
+			FullName: "Ada Lovelace",
+			Socials:  Socials{GitHub: "ada", Discord: "ada"},
+			Terms: map[Term]OfficerTerm{
+				"S23": {Title: "President"},
+			},
+		},
+		{
+			FullName: "Bob",
+			// Bob took the handle "jose" after José changed theirs.
+			Socials: Socials{Discord: "jose", DiscordID: "2"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		match Matcher
+		want  []string
+	}{
+		{"name", ByName("Ada Lovelace"), []string{"Ada Lovelace"}},
+		{"name folded", ByName("  jose   GARCIA "), []string{"José García"}},
+		{"name decomposed", ByName("Jose\u0301 Garci\u0301a"), []string{"José García"}},
+		{"name missing", ByName("Ada"), nil},
+		{"discord id", ByDiscordID("1"), []string{"José García"}},
+		{"empty discord id", ByDiscordID(""), nil},
+		{"discord handle", ByDiscord("@JOSE"), []string{"José García", "Bob"}},
+		{"empty discord handle", ByDiscord(""), nil},
+		{"discord user by id", ByDiscordUser("2", "someone"), []string{"Bob"}},
+		{"discord user without id", ByDiscordUser("3", "ada"), []string{"Ada Lovelace"}},
+		{"discord user ignores reused handle", ByDiscordUser("3", "jose"), nil},
+		{"github", ByGitHub("JGarcia"), []string{"José García"}},
+		{"github with at", ByGitHub("@ada"), []string{"Ada Lovelace"}},
+		{"empty github", ByGitHub(""), nil},
+		{"active", ActiveIn("S23"), []string{"José García", "Ada Lovelace"}},
+		{"title", HoldingTitle("president"), []string{"José García", "Ada Lovelace"}},
+		{"title in", HoldingTitleIn("President", "S23"), []string{"Ada Lovelace"}},
+		{"all", All(ActiveIn("F22"), HoldingTitle("advisor")), []string{"José García"}},
+		{"all of none", All(), []string{"José García", "Ada Lovelace", "Bob"}},
+		{"any", Any(ByGitHub("ada"), ByDiscordID("2")), []string{"Ada Lovelace", "Bob"}},
+		{"any of none", Any(), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, officer := range officers.FindAll(test.match) {
+				got = append(got, officer.FullName)
+			}
+			if !equalStrings(got, test.want) {
+				t.Errorf("matched %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFindOne(t *testing.T) {
+	officers := Officers{
+		{FullName: "Ada", Socials: Socials{Discord: "ada"}},
+		{FullName: "Bob", Socials: Socials{Discord: "ada"}},
+	}
+
+	officer, err := officers.FindOne(ByName("bob"))
+	if err != nil || officer != &officers[1] {
+		t.Errorf("FindOne(bob) = %v, %v, want Bob", officer, err)
+	}
+
+	if _, err := officers.FindOne(ByName("Cat")); !errors.Is(err, ErrOfficerNotFound) {
+		t.Errorf("FindOne(Cat) error = %v, want ErrOfficerNotFound", err)
+	}
+
+	_, err = officers.FindOne(ByDiscord("ada"))
+	var ambiguous *AmbiguousError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Matches) != 2 {
+		t.Errorf("FindOne(discord ada) error = %v, want an AmbiguousError of 2", err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
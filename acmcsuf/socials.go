@@ -0,0 +1,284 @@
+package acmcsuf
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SocialPlatform describes a social platform that Socials has a field for.
+type SocialPlatform struct {
+	// Key is the JSON key of the field, e.g. "github".
+	Key string
+	// Name is the human name of the platform, e.g. "GitHub".
+	Name string
+	// Normalize accepts the value in any of the forms that people commonly
+	// paste, such as a handle, an @handle or a profile URL, and returns its
+	// canonical form. An error explaining the expected form is returned if
+	// the value is invalid.
+	Normalize func(string) (string, error)
+	// URL returns the URL of the profile from the canonical value. It is nil
+	// if the platform has no profile URLs.
+	URL func(string) string
+}
+
+// SocialPlatforms lists the platforms that have their own field in Socials.
+// Discord is not listed, since it's managed by /officer link.
+var SocialPlatforms = []SocialPlatform{
+	{
+		Key:       "website",
+		Name:      "Website",
+		Normalize: NormalizeWebsite,
+		URL:       func(website string) string { return website },
+	},
+	{
+		Key:       "github",
+		Name:      "GitHub",
+		Normalize: NormalizeGitHub,
+		URL:       func(handle string) string { return "https://github.com/" + handle },
+	},
+	{
+		Key:       "linkedin",
+		Name:      "LinkedIn",
+		Normalize: NormalizeLinkedIn,
+		URL:       func(handle string) string { return "https://www.linkedin.com/in/" + handle },
+	},
+	{
+		Key:       "instagram",
+		Name:      "Instagram",
+		Normalize: NormalizeInstagram,
+		URL:       func(handle string) string { return "https://www.instagram.com/" + handle },
+	},
+}
+
+// LookupSocialPlatform returns the platform with the given key, ignoring
+// case.
+func LookupSocialPlatform(key string) (SocialPlatform, bool) {
+	for _, platform := range SocialPlatforms {
+		if strings.EqualFold(platform.Key, key) {
+			return platform, true
+		}
+	}
+	return SocialPlatform{}, false
+}
+
+func (s *Socials) field(key string) *string {
+	switch strings.ToLower(key) {
+	case "website":
+		return &s.Website
+	case "github":
+		return &s.GitHub
+	case "linkedin":
+		return &s.LinkedIn
+	case "instagram":
+		return &s.Instagram
+	default:
+		return nil
+	}
+}
+
+// Get returns the value of the given platform's field.
+func (s Socials) Get(key string) string {
+	if field := s.field(key); field != nil {
+		return *field
+	}
+	return ""
+}
+
+// Set normalizes the value and sets it as the given platform's field. An
+// empty value clears the field.
+func (s *Socials) Set(key, value string) error {
+	platform, ok := LookupSocialPlatform(key)
+	field := s.field(key)
+	if !ok || field == nil {
+		return fmt.Errorf("unknown social platform %q", key)
+	}
+
+	if value == "" {
+		*field = ""
+		return nil
+	}
+
+	canonical, err := platform.Normalize(value)
+	if err != nil {
+		return err
+	}
+
+	*field = canonical
+	return nil
+}
+
+// URL returns the profile URL of the given platform. An empty string is
+// returned if the field is empty or invalid.
+func (s Socials) URL(key string) string {
+	platform, ok := LookupSocialPlatform(key)
+	if !ok || platform.URL == nil {
+		return ""
+	}
+
+	canonical, err := platform.Normalize(s.Get(key))
+	if err != nil {
+		return ""
+	}
+
+	return platform.URL(canonical)
+}
+
+// NormalizeWebsite normalizes a website URL. The scheme defaults to https, and
+// the host is lowercased.
+func NormalizeWebsite(website string) (string, error) {
+	website = strings.TrimSpace(website)
+	if !strings.Contains(website, "://") {
+		website = "https://" + website
+	}
+
+	if err := validateURL(website); err != nil {
+		return "", err
+	}
+
+	u, _ := url.Parse(website)
+	if !strings.Contains(u.Hostname(), ".") {
+		return "", fmt.Errorf("URL %q has no domain, e.g. example.com", website)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.Path == "/" && u.RawQuery == "" && u.Fragment == "" {
+		u.Path = ""
+	}
+
+	return u.String(), nil
+}
+
+// NormalizeGitHub normalizes a GitHub username, given as the username, an
+// @username or a profile URL.
+func NormalizeGitHub(github string) (string, error) {
+	handle, err := socialHandle(github, []string{"github.com"}, "")
+	if err != nil {
+		return "", fmt.Errorf("invalid GitHub username %q: %v", github, err)
+	}
+
+	if len(handle) > 39 ||
+		strings.HasPrefix(handle, "-") ||
+		strings.HasSuffix(handle, "-") ||
+		strings.Contains(handle, "--") ||
+		!isHandle(handle, "-") {
+
+		return "", fmt.Errorf(
+			"invalid GitHub username %q: usernames have up to 39 letters, "+
+				"digits and single hyphens", github)
+	}
+
+	return handle, nil
+}
+
+// NormalizeLinkedIn normalizes a LinkedIn profile name, which is the part
+// after linkedin.com/in/. It may be given as the name or the profile URL.
+func NormalizeLinkedIn(linkedin string) (string, error) {
+	handle, err := socialHandle(linkedin, []string{"linkedin.com"}, "in/")
+	if err != nil {
+		return "", fmt.Errorf("invalid LinkedIn profile %q: %v", linkedin, err)
+	}
+
+	if len(handle) < 3 || len(handle) > 100 || !isHandle(handle, "-") {
+		return "", fmt.Errorf(
+			"invalid LinkedIn profile %q: use the part after linkedin.com/in/, "+
+				"which has 3 to 100 letters, digits and hyphens", linkedin)
+	}
+
+	return handle, nil
+}
+
+// NormalizeInstagram normalizes an Instagram username, given as the username,
+// an @username or a profile URL. Usernames are lowercased.
+func NormalizeInstagram(instagram string) (string, error) {
+	handle, err := socialHandle(instagram, []string{"instagram.com", "instagr.am"}, "")
+	if err != nil {
+		return "", fmt.Errorf("invalid Instagram username %q: %v", instagram, err)
+	}
+
+	handle = strings.ToLower(handle)
+	if len(handle) > 30 ||
+		strings.HasPrefix(handle, ".") ||
+		strings.HasSuffix(handle, ".") ||
+		strings.Contains(handle, "..") ||
+		!isHandle(handle, "._") {
+
+		return "", fmt.Errorf(
+			"invalid Instagram username %q: usernames have up to 30 letters, "+
+				"digits, periods and underscores", instagram)
+	}
+
+	return handle, nil
+}
+
+// socialHandle extracts the handle from a value that is either a handle, an
+// @handle or a profile URL on one of the given hosts. pathPrefix is the part
+// of the path that comes before the handle, if any.
+func socialHandle(value string, hosts []string, pathPrefix string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("empty")
+	}
+
+	if strings.HasPrefix(value, "@") {
+		return strings.TrimPrefix(value, "@"), nil
+	}
+
+	if !strings.Contains(value, "/") {
+		return value, nil
+	}
+
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("malformed URL")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	host = strings.TrimPrefix(host, "m.")
+
+	var known bool
+	for _, h := range hosts {
+		if host == h {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return "", fmt.Errorf("expected a %s URL", hosts[0])
+	}
+
+	path := strings.TrimPrefix(u.Path, "/")
+	if pathPrefix != "" {
+		if !strings.HasPrefix(path, pathPrefix) {
+			return "", fmt.Errorf("expected a %s/%s URL", hosts[0], pathPrefix)
+		}
+		path = strings.TrimPrefix(path, pathPrefix)
+	}
+
+	handle := strings.SplitN(path, "/", 2)[0]
+	if handle == "" {
+		return "", fmt.Errorf("URL has no username")
+	}
+
+	return handle, nil
+}
+
+// isHandle returns true if the handle only has ASCII letters, digits and the
+// given extra characters.
+func isHandle(handle, extra string) bool {
+	for _, r := range handle {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		case strings.ContainsRune(extra, r):
+		default:
+			return false
+		}
+	}
+	return handle != ""
+}
@@ -0,0 +1,98 @@
+package acmcsuf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSocials(t *testing.T) {
+	tests := []struct {
+		name      string
+		normalize func(string) (string, error)
+		in        string
+		want      string
+		err       bool
+	}{
+		{name: "website", normalize: NormalizeWebsite, in: "example.com", want: "https://example.com"},
+		{name: "website slash", normalize: NormalizeWebsite, in: "https://Example.COM/", want: "https://example.com"},
+		{name: "website path", normalize: NormalizeWebsite, in: " http://example.com/Me ", want: "http://example.com/Me"},
+		{name: "website no domain", normalize: NormalizeWebsite, in: "localhost", err: true},
+		{name: "website bad scheme", normalize: NormalizeWebsite, in: "ftp://example.com", err: true},
+
+		{name: "github", normalize: NormalizeGitHub, in: "diamondburned", want: "diamondburned"},
+		{name: "github at", normalize: NormalizeGitHub, in: "@diamondburned", want: "diamondburned"},
+		{name: "github url", normalize: NormalizeGitHub, in: "https://github.com/diamondburned", want: "diamondburned"},
+		{name: "github url without scheme", normalize: NormalizeGitHub, in: "www.github.com/diamondburned/arikawa", want: "diamondburned"},
+		{name: "github hyphen", normalize: NormalizeGitHub, in: "a-b", want: "a-b"},
+		{name: "github double hyphen", normalize: NormalizeGitHub, in: "a--b", err: true},
+		{name: "github leading hyphen", normalize: NormalizeGitHub, in: "-ab", err: true},
+		{name: "github too long", normalize: NormalizeGitHub, in: strings.Repeat("a", 40), err: true},
+		{name: "github other host", normalize: NormalizeGitHub, in: "https://gitlab.com/diamondburned", err: true},
+		{name: "github empty", normalize: NormalizeGitHub, in: " ", err: true},
+
+		{name: "linkedin", normalize: NormalizeLinkedIn, in: "ada-lovelace", want: "ada-lovelace"},
+		{name: "linkedin url", normalize: NormalizeLinkedIn, in: "https://www.linkedin.com/in/ada-lovelace/", want: "ada-lovelace"},
+		{name: "linkedin mobile url", normalize: NormalizeLinkedIn, in: "m.linkedin.com/in/ada", want: "ada"},
+		{name: "linkedin company url", normalize: NormalizeLinkedIn, in: "linkedin.com/company/acm", err: true},
+		{name: "linkedin too short", normalize: NormalizeLinkedIn, in: "ab", err: true},
+		{name: "linkedin underscore", normalize: NormalizeLinkedIn, in: "ada_lovelace", err: true},
+
+		{name: "instagram", normalize: NormalizeInstagram, in: "Ada.Lovelace", want: "ada.lovelace"},
+		{name: "instagram at", normalize: NormalizeInstagram, in: "@ada_l", want: "ada_l"},
+		{name: "instagram url", normalize: NormalizeInstagram, in: "https://instagram.com/ada_l/", want: "ada_l"},
+		{name: "instagram short url", normalize: NormalizeInstagram, in: "instagr.am/ada_l", want: "ada_l"},
+		{name: "instagram double period", normalize: NormalizeInstagram, in: "ada..l", err: true},
+		{name: "instagram trailing period", normalize: NormalizeInstagram, in: "ada.", err: true},
+		{name: "instagram too long", normalize: NormalizeInstagram, in: strings.Repeat("a", 31), err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.normalize(test.in)
+			if test.err {
+				if err == nil {
+					t.Errorf("normalized %q to %q, want error", test.in, got)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("normalized %q to %q, %v, want %q", test.in, got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestSocialsSet(t *testing.T) {
+	var socials Socials
+
+	if err := socials.Set("GitHub", "https://github.com/ada"); err != nil {
+		t.Fatal("Set github:", err)
+	}
+	if socials.GitHub != "ada" {
+		t.Errorf("GitHub = %q, want ada", socials.GitHub)
+	}
+	if url := socials.URL("github"); url != "https://github.com/ada" {
+		t.Errorf("URL(github) = %q", url)
+	}
+
+	if err := socials.Set("github", "a--b"); err == nil {
+		t.Error("Set github to an invalid username succeeded")
+	}
+	if socials.GitHub != "ada" {
+		t.Errorf("GitHub = %q after a failed Set, want ada", socials.GitHub)
+	}
+
+	if err := socials.Set("github", ""); err != nil {
+		t.Fatal("clear github:", err)
+	}
+	if socials.GitHub != "" {
+		t.Errorf("GitHub = %q after clearing", socials.GitHub)
+	}
+	if url := socials.URL("github"); url != "" {
+		t.Errorf("URL(github) = %q after clearing", url)
+	}
+
+	if err := socials.Set("discord", "ada"); err == nil {
+		t.Error("Set discord succeeded, but it's managed by /officer link")
+	}
+}
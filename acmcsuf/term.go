@@ -0,0 +1,330 @@
+package acmcsuf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Term represents a term of an officer. It is the semester code followed by
+// the last two digits of the year, e.g. "F22" for Fall 2022, which is how
+// officers.json writes terms.
+type Term string
+
+// NewTerm returns a new term from the given semester and year. Only the last
+// two digits of the year are kept, so the year must be in the 2000s.
+func NewTerm(semester Semester, year int) Term {
+	return Term(fmt.Sprintf("%s%02d", string(semester), year%100))
+}
+
+// ParseTerm parses the given string into a term. Besides the canonical form
+// ("F22"), semester names, lowercase codes and full years in the 2000s are
+// accepted, optionally separated by spaces, e.g. "Fall 2022" or "f2022".
+func ParseTerm(s string) (Term, error) {
+	s = strings.TrimSpace(s)
+
+	i := strings.IndexFunc(s, unicode.IsDigit)
+	if i < 1 {
+		return "", fmt.Errorf("invalid term %q: missing semester or year", s)
+	}
+
+	semester, err := ParseSemester(strings.TrimSpace(s[:i]))
+	if err != nil {
+		return "", fmt.Errorf("invalid term %q: %w", s, err)
+	}
+
+	digits := s[i:]
+	year, err := strconv.Atoi(digits)
+	if err != nil || (len(digits) != 2 && (len(digits) != 4 || year < 2000 || year > 2099)) {
+		return "", fmt.Errorf("invalid term %q: year %q is neither 2 digits nor in the 2000s", s, digits)
+	}
+
+	return NewTerm(semester, year), nil
+}
+
+// split splits the term into its semester and full year. The year is 0 if
+// it's not a number.
+func (t Term) split() (Semester, int) {
+	i := strings.IndexFunc(string(t), unicode.IsDigit)
+	if i == -1 {
+		return Semester(t), 0
+	}
+
+	year, err := strconv.Atoi(string(t[i:]))
+	if err != nil {
+		return Semester(t[:i]), 0
+	}
+
+	if len(t[i:]) == 2 {
+		year += 2000
+	}
+
+	return Semester(t[:i]), year
+}
+
+// Semester returns the semester of the term.
+func (t Term) Semester() Semester {
+	semester, _ := t.split()
+	return semester
+}
+
+// Year returns the full year of the term, e.g. 2022 for "F22". It returns 0 if the term has no valid
+// year; use ParseTerm or Validate to tell why.
+func (t Term) Year() int {
+	_, year := t.split()
+	return year
+}
+
+// Validate returns an error if the term is invalid.
+func (t Term) Validate() error {
+	parsed, err := ParseTerm(string(t))
+	if err != nil {
+		return err
+	}
+
+	if parsed != t {
+		return fmt.Errorf("invalid term %q: should be written as %q", t, parsed)
+	}
+
+	return nil
+}
+
+// Compare returns -1 if t comes before u, 1 if t comes after u and 0 if they
+// are the same term. Terms are ordered by year, then by the order of their
+// semesters within a year. Invalid terms come before all valid terms.
+func (t Term) Compare(u Term) int {
+	tValid := t.Validate() == nil
+	uValid := u.Validate() == nil
+
+	switch {
+	case !tValid && !uValid:
+		return strings.Compare(string(t), string(u))
+	case !tValid:
+		return -1
+	case !uValid:
+		return 1
+	}
+
+	if t.Year() != u.Year() {
+		return compareInts(t.Year(), u.Year())
+	}
+
+	return compareInts(t.Semester().order(), u.Semester().order())
+}
+
+// Before returns true if t comes before u.
+func (t Term) Before(u Term) bool {
+	return t.Compare(u) < 0
+}
+
+// After returns true if t comes after u.
+func (t Term) After(u Term) bool {
+	return t.Compare(u) > 0
+}
+
+// Next returns the term after t according to the given calendar.
+func (t Term) Next(c Calendar) Term {
+	return c.Next(t)
+}
+
+// Prev returns the term before t according to the given calendar.
+func (t Term) Prev(c Calendar) Term {
+	return c.Prev(t)
+}
+
+// SortTerms sorts the given terms from the earliest to the latest.
+func SortTerms(terms []Term) {
+	sort.Slice(terms, func(i, j int) bool { return terms[i].Before(terms[j]) })
+}
+
+// CurrentTerm returns the term that the given time falls in according to the
+// given calendar.
+func CurrentTerm(c Calendar, now time.Time) Term {
+	return c.Term(now)
+}
+
+// Semester represents a semester.
+type Semester string
+
+const (
+	Winter Semester = "W"
+	Spring Semester = "S"
+	Summer Semester = "SU"
+	Fall   Semester = "F"
+)
+
+// Semesters lists all known semesters in the order that they occur within a
+// year. Winter and Summer are intersessions.
+var Semesters = []Semester{Winter, Spring, Summer, Fall}
+
+// ParseSemester parses the given semester code or name case-insensitively.
+func ParseSemester(s string) (Semester, error) {
+	for _, semester := range Semesters {
+		if strings.EqualFold(s, string(semester)) || strings.EqualFold(s, semester.String()) {
+			return semester, nil
+		}
+	}
+	return "", fmt.Errorf("unknown semester %q", s)
+}
+
+// IsIntersession returns true if the semester is an intersession, i.e. Winter
+// or Summer.
+func (s Semester) IsIntersession() bool {
+	return s == Winter || s == Summer
+}
+
+// order returns the position of the semester within a year or -1 if the
+// semester is unknown.
+func (s Semester) order() int {
+	for i, semester := range Semesters {
+		if semester == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// String returns the human representation of the term.
+func (s Semester) String() string {
+	switch s {
+	case Winter:
+		return "Winter"
+	case Spring:
+		return "Spring"
+	case Summer:
+		return "Summer"
+	case Fall:
+		return "Fall"
+	default:
+		return fmt.Sprintf("Semester(%q)", string(s))
+	}
+}
+
+// SemesterStart describes the day that a semester starts on. A semester lasts
+// until the next semester in the calendar starts.
+type SemesterStart struct {
+	Semester Semester
+	Month    time.Month
+	Day      int
+}
+
+// Calendar describes the semesters of a year and when they start. The
+// semesters must be listed in the order that they occur within a year. Only
+// the semesters in the calendar are considered when stepping through terms,
+// so a calendar without intersessions skips over them.
+type Calendar []SemesterStart
+
+// DefaultCalendar returns the usual calendar, where Spring semesters run from
+// January to May and Fall semesters take up the rest of the year.
+func DefaultCalendar() Calendar {
+	return Calendar{
+		{Semester: Spring, Month: time.January, Day: 1},
+		{Semester: Fall, Month: time.June, Day: 1},
+	}
+}
+
+// IntersessionCalendar returns a calendar that includes the Winter and Summer
+// intersessions.
+func IntersessionCalendar() Calendar {
+	return Calendar{
+		{Semester: Winter, Month: time.January, Day: 1},
+		{Semester: Spring, Month: time.January, Day: 21},
+		{Semester: Summer, Month: time.May, Day: 27},
+		{Semester: Fall, Month: time.August, Day: 19},
+	}
+}
+
+// Term returns the term that the given time falls in. Times before the first
+// semester of the year belong to the last semester of the previous year.
+func (c Calendar) Term(now time.Time) Term {
+	if len(c) == 0 {
+		return ""
+	}
+
+	_, month, day := now.Date()
+	for i := len(c) - 1; i >= 0; i-- {
+		start := c[i]
+		if month > start.Month || (month == start.Month && day >= start.Day) {
+			return NewTerm(start.Semester, now.Year())
+		}
+	}
+
+	return NewTerm(c[len(c)-1].Semester, now.Year()-1)
+}
+
+// Next returns the term after t in the calendar. If t's semester is not in
+// the calendar, then the first term of the calendar after t is returned.
+func (c Calendar) Next(t Term) Term {
+	if len(c) == 0 {
+		return ""
+	}
+
+	order := t.Semester().order()
+	for _, start := range c {
+		if start.Semester.order() > order {
+			return NewTerm(start.Semester, t.Year())
+		}
+	}
+
+	return NewTerm(c[0].Semester, t.Year()+1)
+}
+
+// Prev returns the term before t in the calendar. If t's semester is not in
+// the calendar, then the last term of the calendar before t is returned.
+func (c Calendar) Prev(t Term) Term {
+	if len(c) == 0 {
+		return ""
+	}
+
+	order := t.Semester().order()
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].Semester.order() < order {
+			return NewTerm(c[i].Semester, t.Year())
+		}
+	}
+
+	return NewTerm(c[len(c)-1].Semester, t.Year()-1)
+}
+
+// Range returns all terms in the calendar from the first to the last term
+// inclusively. Nil is returned if either term is invalid or if last comes
+// before first.
+func (c Calendar) Range(first, last Term) []Term {
+	if len(c) == 0 || first.Validate() != nil || last.Validate() != nil || first.After(last) {
+		return nil
+	}
+
+	var terms []Term
+	if c.contains(first.Semester()) {
+		terms = append(terms, first)
+	}
+
+	for term := c.Next(first); !term.After(last); term = c.Next(term) {
+		terms = append(terms, term)
+	}
+
+	return terms
+}
+
+func (c Calendar) contains(semester Semester) bool {
+	for _, start := range c {
+		if start.Semester == semester {
+			return true
+		}
+	}
+	return false
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
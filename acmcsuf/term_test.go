@@ -0,0 +1,230 @@
+package acmcsuf
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTerm(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Term
+		err  bool
+	}{
+		{in: "F22", want: "F22"},
+		{in: "f22", want: "F22"},
+		{in: "F2022", want: "F22"},
+		{in: "Fall 2022", want: "F22"},
+		{in: " spring  23 ", want: "S23"},
+		{in: "SU2023", want: "SU23"},
+		{in: "Winter 2024", want: "W24"},
+		{in: "S2000", want: "S00"},
+		{in: "F05", want: "F05"},
+		{in: "F2", err: true},
+		{in: "F022", err: true},
+		{in: "F1999", err: true},
+		{in: "F2100", err: true},
+		{in: "F20222", err: true},
+		{in: "F22a", err: true},
+		{in: "2022", err: true},
+		{in: "Autumn 2022", err: true},
+		{in: "F", err: true},
+		{in: "", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseTerm(test.in)
+		if test.err {
+			if err == nil {
+				t.Errorf("ParseTerm(%q) = %q, want error", test.in, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("ParseTerm(%q) = %q, %v, want %q", test.in, got, err, test.want)
+		}
+	}
+}
+
+func TestTermValidate(t *testing.T) {
+	for term, valid := range map[Term]bool{
+		"F22":   true,
+		"SU23":  true,
+		"F2022": false,
+		"f22":   false,
+		"F2":    false,
+		"X22":   false,
+		"":      false,
+	} {
+		if err := term.Validate(); (err == nil) != valid {
+			t.Errorf("Term(%q).Validate() = %v, want valid = %v", term, err, valid)
+		}
+	}
+}
+
+// TestOfficersJSONTerms checks the terms in the form that officers.json on
+// acmcsuf.com writes them, which is the semester followed by a 2-digit year.
+func TestOfficersJSONTerms(t *testing.T) {
+	const officersJSON = `[
+  {
+    "fullName": "Ada Lovelace",
+    "picture": "ada-lovelace.webp",
+    "socials": {},
+    "terms": {
+      "F20": { "title": "President", "tier": 0 },
+      "S21": { "title": "President", "tier": 0 },
+      "F21": { "title": "Advisor", "tier": 1 }
+    }
+  },
+  {
+    "fullName": "Bob",
+    "picture": "",
+    "socials": {},
+    "terms": {
+      "S22": { "title": "President", "tier": 0 },
+      "F22": { "title": "President", "tier": 0 },
+      "S23": { "title": "Advisor", "tier": 1 }
+    }
+  }
+]`
+
+	var officers Officers
+	if err := json.Unmarshal([]byte(officersJSON), &officers); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := officers.Validate(Tiers{"President", "Advisor"}); err != nil {
+		t.Error("Validate:", err)
+	}
+
+	var terms []Term
+	for _, officer := range officers {
+		for term := range officer.Terms {
+			terms = append(terms, term)
+		}
+	}
+	SortTerms(terms)
+
+	want := []Term{"F20", "S21", "F21", "S22", "F22", "S23"}
+	if !reflect.DeepEqual(terms, want) {
+		t.Errorf("sorted terms = %q, want %q", terms, want)
+	}
+
+	if year := Term("F20").Year(); year != 2020 {
+		t.Errorf("Year of F20 = %d, want 2020", year)
+	}
+	if next := Term("F22").Next(DefaultCalendar()); next != "S23" {
+		t.Errorf("term after F22 = %q, want S23", next)
+	}
+}
+
+func TestTermCompare(t *testing.T) {
+	tests := []struct {
+		t, u Term
+		want int
+	}{
+		{"F22", "F22", 0},
+		{"S22", "F22", -1},
+		{"F22", "S23", -1},
+		{"W23", "S23", -1},
+		{"S23", "SU23", -1},
+		{"SU23", "F23", -1},
+		{"F23", "W23", 1},
+		{"bad", "F22", -1},
+		{"F22", "bad", 1},
+	}
+
+	for _, test := range tests {
+		if got := test.t.Compare(test.u); got != test.want {
+			t.Errorf("Term(%q).Compare(%q) = %d, want %d", test.t, test.u, got, test.want)
+		}
+		if got := test.u.Compare(test.t); got != -test.want {
+			t.Errorf("Term(%q).Compare(%q) = %d, want %d", test.u, test.t, got, -test.want)
+		}
+	}
+
+	terms := []Term{"F23", "S22", "SU23", "F22", "W23"}
+	SortTerms(terms)
+	want := []Term{"S22", "F22", "W23", "SU23", "F23"}
+	if !reflect.DeepEqual(terms, want) {
+		t.Errorf("SortTerms = %q, want %q", terms, want)
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	tests := []struct {
+		name     string
+		calendar Calendar
+		term     Term
+		next     Term
+		prev     Term
+	}{
+		{"default fall", DefaultCalendar(), "F22", "S23", "S22"},
+		{"default spring", DefaultCalendar(), "S23", "F23", "F22"},
+		{"default skips summer", DefaultCalendar(), "SU23", "F23", "S23"},
+		{"intersession spring", IntersessionCalendar(), "S23", "SU23", "W23"},
+		{"intersession fall", IntersessionCalendar(), "F22", "W23", "SU22"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.term.Next(test.calendar); got != test.next {
+				t.Errorf("Next = %q, want %q", got, test.next)
+			}
+			if got := test.term.Prev(test.calendar); got != test.prev {
+				t.Errorf("Prev = %q, want %q", got, test.prev)
+			}
+		})
+	}
+}
+
+func TestCurrentTerm(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		calendar Calendar
+		now      time.Time
+		want     Term
+	}{
+		{DefaultCalendar(), date(2023, time.January, 1), "S23"},
+		{DefaultCalendar(), date(2023, time.May, 31), "S23"},
+		{DefaultCalendar(), date(2023, time.June, 1), "F23"},
+		{DefaultCalendar(), date(2023, time.December, 31), "F23"},
+		{IntersessionCalendar(), date(2023, time.January, 20), "W23"},
+		{IntersessionCalendar(), date(2023, time.January, 21), "S23"},
+		{IntersessionCalendar(), date(2023, time.July, 1), "SU23"},
+		{Calendar{{Semester: Fall, Month: time.August, Day: 1}}, date(2023, time.July, 1), "F22"},
+	}
+
+	for _, test := range tests {
+		if got := CurrentTerm(test.calendar, test.now); got != test.want {
+			t.Errorf("CurrentTerm(%s) = %q, want %q", test.now.Format("2006-01-02"), got, test.want)
+		}
+	}
+}
+
+func TestCalendarRange(t *testing.T) {
+	tests := []struct {
+		calendar    Calendar
+		first, last Term
+		want        []Term
+	}{
+		{DefaultCalendar(), "F22", "F23", []Term{"F22", "S23", "F23"}},
+		{DefaultCalendar(), "F22", "F22", []Term{"F22"}},
+		{DefaultCalendar(), "SU22", "S23", []Term{"F22", "S23"}},
+		{IntersessionCalendar(), "SU22", "W23", []Term{"SU22", "F22", "W23"}},
+		{DefaultCalendar(), "F23", "F22", nil},
+		{DefaultCalendar(), "F2022", "F23", nil},
+	}
+
+	for _, test := range tests {
+		got := test.calendar.Range(test.first, test.last)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Range(%q, %q) = %q, want %q", test.first, test.last, got, test.want)
+		}
+	}
+}
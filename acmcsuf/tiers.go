@@ -0,0 +1,95 @@
+package acmcsuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LoadTiers decodes tiers.json from the given reader and checks it.
+func LoadTiers(r io.Reader) (Tiers, error) {
+	var tiers Tiers
+	if err := json.NewDecoder(r).Decode(&tiers); err != nil {
+		return nil, errors.Wrap(err, "cannot decode tiers")
+	}
+
+	if err := tiers.Validate(); err != nil {
+		return nil, err
+	}
+
+	return tiers, nil
+}
+
+// Validate returns an error if any title is empty or duplicated.
+func (t Tiers) Validate() error {
+	seen := make(map[string]int, len(t))
+	for i, title := range t {
+		if strings.TrimSpace(title) == "" {
+			return fmt.Errorf("tier %d has an empty title", i)
+		}
+		if j, ok := seen[strings.ToLower(title)]; ok {
+			return fmt.Errorf("tier %d has the same title %q as tier %d", i, title, j)
+		}
+		seen[strings.ToLower(title)] = i
+	}
+	return nil
+}
+
+// Index returns the tier of the given title. Titles are matched
+// case-insensitively. If there is no such title, then false is returned.
+func (t Tiers) Index(title string) (int, bool) {
+	for i, tierTitle := range t {
+		if tierTitle == title {
+			return i, true
+		}
+	}
+	for i, tierTitle := range t {
+		if strings.EqualFold(tierTitle, title) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Title returns the title of the given tier. If the tier is out of range, then
+// false is returned.
+func (t Tiers) Title(i int) (string, bool) {
+	if i < 0 || i >= len(t) {
+		return "", false
+	}
+	return t[i], true
+}
+
+// NewOfficerTerm creates an OfficerTerm for the given title, looking up its
+// tier. The title is normalized to how it's spelled in the tiers.
+func (t Tiers) NewOfficerTerm(title string) (OfficerTerm, error) {
+	tier, ok := t.Index(title)
+	if !ok {
+		return OfficerTerm{}, fmt.Errorf("unknown title %q, see tiers.json for known titles", title)
+	}
+	return OfficerTerm{Title: t[tier], Tier: tier}, nil
+}
+
+// Check returns an error if the term's tier and title don't agree with the
+// given tiers.
+func (t OfficerTerm) Check(tiers Tiers) error {
+	title, ok := tiers.Title(t.Tier)
+	if !ok {
+		return fmt.Errorf("tier %d is out of range of %d tiers", t.Tier, len(tiers))
+	}
+
+	if title != t.Title {
+		if tier, ok := tiers.Index(t.Title); ok {
+			if tier == t.Tier {
+				return fmt.Errorf("title %q should be written as %q", t.Title, title)
+			}
+			return fmt.Errorf("title %q is tier %d, not %d", t.Title, tier, t.Tier)
+		}
+		return fmt.Errorf("tier %d is titled %q, not %q", t.Tier, title, t.Title)
+	}
+
+	return nil
+}
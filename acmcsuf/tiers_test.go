@@ -0,0 +1,198 @@
+package acmcsuf
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testTiersJSON is in the form of tiers.json on acmcsuf.com: the titles from
+// the top of the board down, indented by two spaces.
+const testTiersJSON = `[
+  "President",
+  "Vice President",
+  "Secretary",
+  "Treasurer",
+  "Webmaster",
+  "AI President",
+  "Algo President",
+  "Design President",
+  "Dev President",
+  "Advisor"
+]
+`
+
+func TestLoadTiers(t *testing.T) {
+	tiers, err := LoadTiers(strings.NewReader(testTiersJSON))
+	if err != nil {
+		t.Fatal("LoadTiers:", err)
+	}
+	if len(tiers) != 10 || tiers[0] != "President" || tiers[9] != "Advisor" {
+		t.Fatalf("LoadTiers = %q", tiers)
+	}
+
+	b, err := json.MarshalIndent(tiers, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b) + "\n"; got != testTiersJSON {
+		t.Errorf("tiers don't round-trip:\n%s\nwant:\n%s", got, testTiersJSON)
+	}
+
+	for name, data := range map[string]string{
+		"not JSON":    `["President"`,
+		"not titles":  `[{"title": "President"}]`,
+		"empty title": `["President", " "]`,
+		"duplicate":   `["President", "Advisor", "president"]`,
+		"object":      `{}`,
+	} {
+		if tiers, err := LoadTiers(strings.NewReader(data)); err == nil {
+			t.Errorf("LoadTiers of %s = %q, want error", name, tiers)
+		}
+	}
+}
+
+func TestTiersValidate(t *testing.T) {
+	tests := []struct {
+		tiers Tiers
+		err   string
+	}{
+		{tiers: Tiers{"President", "Advisor"}},
+		{tiers: Tiers{}},
+		{tiers: Tiers{"President", ""}, err: "tier 1 has an empty title"},
+		{tiers: Tiers{"President", "Advisor", "ADVISOR"}, err: `tier 2 has the same title "ADVISOR" as tier 1`},
+	}
+
+	for _, test := range tests {
+		err := test.tiers.Validate()
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%q.Validate() = %v", test.tiers, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%q.Validate() = %v, want %q", test.tiers, err, test.err)
+		}
+	}
+}
+
+func TestTiersIndex(t *testing.T) {
+	// Tiers that only differ by case are invalid, but exact matches still win
+	// if they exist.
+	tiers := Tiers{"President", "Vice President", "advisor", "Advisor"}
+
+	for title, want := range map[string]int{
+		"President":      0,
+		"vice president": 1,
+		"advisor":        2,
+		"Advisor":        3,
+		"ADVISOR":        2,
+		"Treasurer":      -1,
+		"":               -1,
+	} {
+		got, ok := tiers.Index(title)
+		if got != want || ok != (want != -1) {
+			t.Errorf("Index(%q) = %d, %v, want %d", title, got, ok, want)
+		}
+	}
+}
+
+func TestTiersTitle(t *testing.T) {
+	tiers := Tiers{"President", "Advisor"}
+
+	for i, want := range map[int]string{-1: "", 0: "President", 1: "Advisor", 2: ""} {
+		got, ok := tiers.Title(i)
+		if got != want || ok != (want != "") {
+			t.Errorf("Title(%d) = %q, %v, want %q", i, got, ok, want)
+		}
+	}
+}
+
+func TestNewOfficerTerm(t *testing.T) {
+	tiers := Tiers{"President", "Vice President", "Advisor"}
+
+	term, err := tiers.NewOfficerTerm("vice president")
+	if err != nil {
+		t.Fatal("NewOfficerTerm:", err)
+	}
+	if want := (OfficerTerm{Title: "Vice President", Tier: 1}); term != want {
+		t.Errorf("NewOfficerTerm = %+v, want %+v", term, want)
+	}
+	if err := term.Check(tiers); err != nil {
+		t.Errorf("new term doesn't pass Check: %v", err)
+	}
+
+	_, err = tiers.NewOfficerTerm("Treasurer")
+	if err == nil || err.Error() != `unknown title "Treasurer", see tiers.json for known titles` {
+		t.Errorf("NewOfficerTerm of an unknown title = %v", err)
+	}
+}
+
+func TestOfficerTermCheck(t *testing.T) {
+	tiers := Tiers{"President", "Vice President", "Advisor"}
+
+	tests := []struct {
+		term OfficerTerm
+		err  string
+	}{
+		{term: OfficerTerm{Title: "Advisor", Tier: 2}},
+		{term: OfficerTerm{Title: "Advisor", Tier: 3}, err: "tier 3 is out of range of 3 tiers"},
+		{term: OfficerTerm{Title: "Advisor", Tier: -1}, err: "tier -1 is out of range of 3 tiers"},
+		{term: OfficerTerm{Title: "Advisor", Tier: 0}, err: `title "Advisor" is tier 2, not 0`},
+		{term: OfficerTerm{Title: "Treasurer", Tier: 1}, err: `tier 1 is titled "Vice President", not "Treasurer"`},
+		// Check wants the title spelled as it is in the tiers.
+		{term: OfficerTerm{Title: "advisor", Tier: 2}, err: `title "advisor" should be written as "Advisor"`},
+	}
+
+	for _, test := range tests {
+		err := test.term.Check(tiers)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("Check(%+v) = %v", test.term, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != test.err {
+			t.Errorf("Check(%+v) = %v, want %q", test.term, err, test.err)
+		}
+	}
+}
+
+func TestOfficersJSONTiers(t *testing.T) {
+	tiers, err := LoadTiers(strings.NewReader(testTiersJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var officers Officers
+	err = json.Unmarshal([]byte(`[{
+		"fullName": "Ada",
+		"picture": "",
+		"socials": {},
+		"terms": {
+			"F21": { "title": "Webmaster", "tier": 4 },
+			"S22": { "title": "Vice President", "tier": 1 },
+			"F22": { "title": "President", "tier": 0 }
+		}
+	}]`), &officers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := officers.Validate(tiers); err != nil {
+		t.Error("Validate:", err)
+	}
+
+	want := map[Term]OfficerTerm{}
+	for term, officerTerm := range officers[0].Terms {
+		want[term], err = tiers.NewOfficerTerm(officerTerm.Title)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(officers[0].Terms, want) {
+		t.Errorf("terms = %+v, want the tiers of their titles %+v", officers[0].Terms, want)
+	}
+}
@@ -0,0 +1,165 @@
+package acmcsuf
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// FieldError is a problem with a field of an officer.
+type FieldError struct {
+	// Index is the index of the officer in Officers.
+	Index int
+	// Officer is the full name of the officer.
+	Officer string
+	// Field is the path to the field, e.g. "socials.website". It is empty if
+	// the problem is with the officer as a whole.
+	Field string
+	// Err is the problem.
+	Err error
+}
+
+// Path returns the path to the field, e.g. `[3] "John Doe".socials.website`.
+func (e FieldError) Path() string {
+	path := fmt.Sprintf("[%d] %q", e.Index, e.Officer)
+	if e.Field != "" {
+		path += "." + e.Field
+	}
+	return path
+}
+
+// Error implements error.
+func (e FieldError) Error() string {
+	return e.Path() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying problem.
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is a list of problems found by Officers.Validate.
+type ValidationError []FieldError
+
+// Error implements error.
+func (e ValidationError) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problems:", len(e))
+	for _, err := range e {
+		b.WriteString("\n- ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Validate checks the officers for problems, such as duplicate names or
+// Discord IDs, invalid terms, tiers and titles that don't agree with the given
+// tiers and social links that are malformed or not in their canonical form.
+// If there are any, then a ValidationError listing all of them is returned.
+func (o Officers) Validate(tiers Tiers) error {
+	var errs ValidationError
+
+	names := make(map[string]int, len(o))
+	discordIDs := make(map[string]int, len(o))
+
+	for i, officer := range o {
+		fail := func(field string, format string, v ...interface{}) {
+			errs = append(errs, FieldError{
+				Index:   i,
+				Officer: officer.FullName,
+				Field:   field,
+				Err:     fmt.Errorf(format, v...),
+			})
+		}
+
+		if strings.TrimSpace(officer.FullName) == "" {
+			fail("fullName", "full name is empty")
+		} else if j, ok := names[officer.FullName]; ok {
+			fail("fullName", "duplicate of officer [%d]", j)
+		} else {
+			names[officer.FullName] = i
+		}
+
+		if id := officer.Socials.DiscordID; id != "" {
+			if !isSnowflake(id) {
+				fail("socials.discordId", "%q is not a Discord ID", id)
+			} else if j, ok := discordIDs[id]; ok {
+				fail("socials.discordId", "already linked to officer [%d]", j)
+			} else {
+				discordIDs[id] = i
+			}
+		}
+
+		for _, platform := range SocialPlatforms {
+			value := officer.Socials.Get(platform.Key)
+			if value == "" {
+				continue
+			}
+
+			canonical, err := platform.Normalize(value)
+			if err != nil {
+				fail("socials."+platform.Key, "%v", err)
+			} else if canonical != value {
+				fail("socials."+platform.Key, "%q should be written as %q", value, canonical)
+			}
+		}
+
+		terms := make([]Term, 0, len(officer.Terms))
+		for term := range officer.Terms {
+			terms = append(terms, term)
+		}
+		SortTerms(terms)
+
+		for _, term := range terms {
+			field := "terms." + string(term)
+
+			if err := term.Validate(); err != nil {
+				fail(field, "%v", err)
+			}
+
+			if err := officer.Terms[term].Check(tiers); err != nil {
+				fail(field, "%v", err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func validateURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("malformed URL %q", s)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL %q is not http or https", s)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("URL %q has no host", s)
+	}
+
+	return nil
+}
+
+// isSnowflake returns true if s looks like a Discord ID.
+func isSnowflake(s string) bool {
+	if s == "" || s == "0" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return len(s) <= 20
+}
@@ -0,0 +1,145 @@
+package acmcsuf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOfficersValidate(t *testing.T) {
+	tiers := Tiers{"President", "Advisor"}
+	president := map[Term]OfficerTerm{"F22": {Title: "President", Tier: 0}}
+
+	type problem struct {
+		path string
+		err  string
+	}
+
+	tests := []struct {
+		name     string
+		officers Officers
+		want     []problem
+	}{
+		{
+			name: "valid",
+			officers: Officers{
+				{FullName: "Ada", Socials: Socials{DiscordID: "1", GitHub: "ada"}, Terms: president},
+				{FullName: "Bob", Socials: Socials{DiscordID: "2"}},
+			},
+		},
+		{
+			name:     "empty name",
+			officers: Officers{{FullName: " "}},
+			want:     []problem{{`[0] " ".fullName`, "full name is empty"}},
+		},
+		{
+			name:     "duplicate name",
+			officers: Officers{{FullName: "Ada"}, {FullName: "Bob"}, {FullName: "Ada"}},
+			want:     []problem{{`[2] "Ada".fullName`, "duplicate of officer [0]"}},
+		},
+		{
+			name:     "bad Discord ID",
+			officers: Officers{{FullName: "Ada", Socials: Socials{DiscordID: "ada#1234"}}},
+			want:     []problem{{`[0] "Ada".socials.discordId`, `"ada#1234" is not a Discord ID`}},
+		},
+		{
+			name: "duplicate Discord ID",
+			officers: Officers{
+				{FullName: "Ada", Socials: Socials{DiscordID: "1"}},
+				{FullName: "Bob", Socials: Socials{DiscordID: "1"}},
+			},
+			want: []problem{{`[1] "Bob".socials.discordId`, "already linked to officer [0]"}},
+		},
+		{
+			name:     "non-canonical social",
+			officers: Officers{{FullName: "Ada", Socials: Socials{GitHub: "https://github.com/ada"}}},
+			want:     []problem{{`[0] "Ada".socials.github`, `"https://github.com/ada" should be written as "ada"`}},
+		},
+		{
+			name:     "malformed social",
+			officers: Officers{{FullName: "Ada", Socials: Socials{Website: "ftp://example.com"}}},
+			want:     []problem{{`[0] "Ada".socials.website`, `URL "ftp://example.com" is not http or https`}},
+		},
+		{
+			name: "bad term",
+			officers: Officers{{
+				FullName: "Ada",
+				Terms:    map[Term]OfficerTerm{"Fall22": {Title: "President", Tier: 0}},
+			}},
+			want: []problem{{`[0] "Ada".terms.Fall22`, `invalid term "Fall22": should be written as "F22"`}},
+		},
+		{
+			name: "unknown tier",
+			officers: Officers{{
+				FullName: "Ada",
+				Terms:    map[Term]OfficerTerm{"F22": {Title: "Treasurer", Tier: 5}},
+			}},
+			want: []problem{{`[0] "Ada".terms.F22`, "tier 5 is out of range of 2 tiers"}},
+		},
+		{
+			name: "mismatched title",
+			officers: Officers{{
+				FullName: "Ada",
+				Terms:    map[Term]OfficerTerm{"F22": {Title: "Advisor", Tier: 0}},
+			}},
+			want: []problem{{`[0] "Ada".terms.F22`, `title "Advisor" is tier 1, not 0`}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.officers.Validate(tiers)
+			if len(test.want) == 0 {
+				if err != nil {
+					t.Fatal("Validate:", err)
+				}
+				return
+			}
+
+			var verr ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate = %v, want a ValidationError", err)
+			}
+			if len(verr) != len(test.want) {
+				t.Fatalf("Validate found %d problems, want %d: %v", len(verr), len(test.want), err)
+			}
+			for i, want := range test.want {
+				if got := verr[i].Path(); got != want.path {
+					t.Errorf("problem %d path = %s, want %s", i, got, want.path)
+				}
+				if got := verr[i].Err.Error(); got != want.err {
+					t.Errorf("problem %d = %q, want %q", i, got, want.err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	officers := Officers{
+		{FullName: "Ada", Socials: Socials{DiscordID: "1"}},
+		{
+			FullName: "Ada",
+			Socials:  Socials{DiscordID: "1", Instagram: "@ada"},
+			Terms:    map[Term]OfficerTerm{"F22": {Title: "President", Tier: 1}},
+		},
+	}
+
+	err := officers.Validate(Tiers{"President", "Advisor"})
+	if err == nil {
+		t.Fatal("Validate succeeded")
+	}
+
+	const want = `4 problems:
+- [1] "Ada".fullName: duplicate of officer [0]
+- [1] "Ada".socials.discordId: already linked to officer [0]
+- [1] "Ada".socials.instagram: "@ada" should be written as "ada"
+- [1] "Ada".terms.F22: title "President" is tier 0, not 1`
+	if err.Error() != want {
+		t.Errorf("Validate error:\n%s\nwant:\n%s", err, want)
+	}
+
+	single := ValidationError{{Index: 0, Officer: "Ada", Err: errors.New("oops")}}
+	if got := single.Error(); got != `[0] "Ada": oops` {
+		t.Errorf("single problem error = %q", got)
+	}
+}
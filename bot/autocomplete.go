@@ -0,0 +1,230 @@
+package bot
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/api/cmdroute"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/officer-data/acmcsuf"
+)
+
+// maxChoices is the maximum number of autocomplete choices Discord accepts.
+const maxChoices = 25
+
+// autocompleteOfficer autocompletes the full_name option with the names of
+// existing officers.
+func (h *Handler) autocompleteOfficer(ctx context.Context, data cmdroute.AutocompleteData) api.AutocompleteChoices {
+	focused := data.Options.Focused()
+	if focused.Name != "full_name" {
+		return nil
+	}
+
+	choices := api.AutocompleteStringChoices{}
+
+	upstream := h.cachedUpstream()
+	if upstream == nil {
+		return choices
+	}
+
+	names := make([]string, len(upstream.Officers))
+	for i, officer := range upstream.Officers {
+		names[i] = officer.FullName
+	}
+
+	for _, name := range fuzzyFilter(focused.String(), names) {
+		choices = append(choices, discord.StringChoice{Name: name, Value: name})
+	}
+
+	return choices
+}
+
+// autocompleteField autocompletes the field option with the extra fields that
+// are already used by other officers.
+func (h *Handler) autocompleteField(ctx context.Context, data cmdroute.AutocompleteData) api.AutocompleteChoices {
+	focused := data.Options.Focused()
+	if focused.Name != "field" {
+		return nil
+	}
+
+	choices := api.AutocompleteStringChoices{}
+
+	upstream := h.cachedUpstream()
+	if upstream == nil {
+		return choices
+	}
+
+	seen := make(map[string]struct{})
+	var fields []string
+	addField := func(field string) {
+		if _, ok := seen[field]; !ok {
+			seen[field] = struct{}{}
+			fields = append(fields, field)
+		}
+	}
+
+	for _, officer := range upstream.Officers {
+		for _, key := range officer.Extra.Keys() {
+			addField(key)
+		}
+		for _, key := range officer.Socials.Extra.Keys() {
+			addField("socials." + key)
+		}
+	}
+
+	for _, field := range fuzzyFilter(focused.String(), fields) {
+		choices = append(choices, discord.StringChoice{Name: field, Value: field})
+	}
+
+	return choices
+}
+
+// autocompleteTerm autocompletes the title and term options of commands that
+// take a term.
+func (h *Handler) autocompleteTerm(ctx context.Context, data cmdroute.AutocompleteData) api.AutocompleteChoices {
+	focused := data.Options.Focused()
+	upstream := h.cachedUpstream()
+
+	switch focused.Name {
+	case "title":
+		choices := api.AutocompleteStringChoices{}
+		if upstream == nil {
+			return choices
+		}
+
+		for _, title := range fuzzyFilter(focused.String(), upstream.Tiers) {
+			choices = append(choices, discord.StringChoice{Name: title, Value: title})
+		}
+
+		return choices
+
+	case "term":
+		current := acmcsuf.CurrentTerm(h.Calendar, time.Now())
+		terms := map[acmcsuf.Term]struct{}{
+			current:                  {},
+			current.Next(h.Calendar): {},
+		}
+		if upstream != nil {
+			for _, officer := range upstream.Officers {
+				for term := range officer.Terms {
+					if term.Validate() == nil {
+						terms[term] = struct{}{}
+					}
+				}
+			}
+		}
+
+		query := strings.ToLower(strings.Join(strings.Fields(focused.String()), " "))
+
+		sortedTerms := make([]acmcsuf.Term, 0, len(terms))
+		for term := range terms {
+			name := strings.ToLower(termName(term))
+			if strings.HasPrefix(strings.ToLower(string(term)), query) || strings.HasPrefix(name, query) {
+				sortedTerms = append(sortedTerms, term)
+			}
+		}
+		// Most recent first.
+		acmcsuf.SortTerms(sortedTerms)
+		for i, j := 0, len(sortedTerms)-1; i < j; i, j = i+1, j-1 {
+			sortedTerms[i], sortedTerms[j] = sortedTerms[j], sortedTerms[i]
+		}
+
+		if len(sortedTerms) > maxChoices {
+			sortedTerms = sortedTerms[:maxChoices]
+		}
+
+		choices := api.AutocompleteStringChoices{}
+		for _, term := range sortedTerms {
+			choices = append(choices, discord.StringChoice{
+				Name:  termName(term) + " (" + string(term) + ")",
+				Value: string(term),
+			})
+		}
+
+		return choices
+
+	default:
+		return nil
+	}
+}
+
+// termName returns the human name of the term, e.g. "Fall 2022".
+func termName(term acmcsuf.Term) string {
+	return term.Semester().String() + " " + strconv.Itoa(term.Year())
+}
+
+// fuzzyFilter returns the candidates matching the query, best match first. At
+// most maxChoices candidates are returned.
+func fuzzyFilter(query string, candidates []string) []string {
+	type match struct {
+		candidate string
+		score     int
+	}
+
+	var matches []match
+	for _, candidate := range candidates {
+		if score, ok := fuzzyScore(query, candidate); ok {
+			matches = append(matches, match{candidate, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	if len(matches) > maxChoices {
+		matches = matches[:maxChoices]
+	}
+
+	filtered := make([]string, len(matches))
+	for i, match := range matches {
+		filtered[i] = match.candidate
+	}
+
+	return filtered
+}
+
+// fuzzyScore scores how well the query matches the candidate. Higher is
+// better. Prefix matches rank above word prefix matches, which rank above
+// substring matches, which rank above subsequence matches. ok is false if the
+// query doesn't match at all.
+func fuzzyScore(query, candidate string) (score int, ok bool) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	candidate = strings.ToLower(candidate)
+
+	if query == "" {
+		return 0, true
+	}
+
+	switch {
+	case strings.HasPrefix(candidate, query):
+		return 300, true
+	case strings.Contains(candidate, " "+query):
+		return 200, true
+	case strings.Contains(candidate, query):
+		return 100, true
+	}
+
+	// Subsequence match, preferring fewer skipped characters.
+	queryRunes := []rune(query)
+	skipped := 0
+	i := 0
+	for _, r := range candidate {
+		if i < len(queryRunes) && r == queryRunes[i] {
+			i++
+		} else if !unicode.IsSpace(r) {
+			skipped++
+		}
+	}
+
+	if i < len(queryRunes) {
+		return 0, false
+	}
+
+	return 100 - skipped, true
+}
@@ -1,93 +1,341 @@
 package bot
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/diamondburned/arikawa/v3/api"
 	"github.com/diamondburned/arikawa/v3/api/cmdroute"
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/utils/json/option"
 	"github.com/diamondburned/officer-data/acmcsuf"
+	"github.com/diamondburned/officer-data/internal/forge"
 	"github.com/diamondburned/officer-data/internal/gitwork"
 	"github.com/pkg/errors"
 )
 
-// initUserWorkspace gives each Discord user a workspace in the git repo.
-func (h *Handler) initUserWorkspace(ctx context.Context, guildID discord.GuildID, user *discord.User) (*gitwork.PooledRepository, error) {
-	dirPath := filepath.Join(guildID.String(), user.ID.String())
+// initUserWorkspace gives each Discord user a workspace in the git repo. The
+// workspace is acquired so that it isn't evicted while it's used, and the
+// caller must release it.
+func (h *Handler) initUserWorkspace(ctx context.Context, ev *discord.InteractionEvent) (*gitwork.PooledRepository, error) {
+	dirPath := filepath.Join(ev.GuildID.String(), ev.SenderID().String())
 
-	repo, err := h.gits.Clone(ctx, true, dirPath)
+	repo, err := h.gits.CloneAcquired(ctx, false, dirPath)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to clone git repo")
 	}
 
+	if owner := workspaceOwner(ev); repo.Info().Owner != owner {
+		repo.SetOwner(owner)
+	}
+
 	return repo, nil
 }
 
+// workspaceOwner describes the owner of the sender's workspace for the pool's
+// index.
+func workspaceOwner(ev *discord.InteractionEvent) string {
+	return fmt.Sprintf("%s (%s)", discordHandle(*ev.Sender()), ev.SenderID())
+}
+
 type commit struct {
 	Title string
 	Body  string
+	// Files holds other files to write and commit along with officers.json,
+	// keyed by their path in the repository. A nil value removes the file.
+	Files map[string][]byte
 }
 
-func (h *Handler) updateOfficers(ctx context.Context, command cmdroute.CommandData, updateFn func(officers *acmcsuf.Officers) (commit, error)) *api.InteractionResponseData {
-	repo, err := h.initUserWorkspace(ctx, command.Event.GuildID, command.Event.User)
+type updateOfficersFunc func(officers *acmcsuf.Officers) (commit, error)
+
+// updateOfficers previews the changes made by updateFn to the user. The
+// changes are only committed once the user confirms them, and exactly as they
+// were previewed.
+func (h *Handler) updateOfficers(ctx context.Context, command cmdroute.CommandData, updateFn updateOfficersFunc) *api.InteractionResponseData {
+	repo, err := h.initUserWorkspace(ctx, command.Event)
 	if err != nil {
 		return errorResponse(err)
 	}
+	defer repo.Release()
 
-	f, err := repo.OpenFile(acmcsuf.OfficersJSONPath, os.O_RDWR|gitwork.LockFile)
+	sync, err := repo.Sync(ctx)
 	if err != nil {
-		return errorResponse(errors.Wrap(err, "failed to open officers.json"))
+		return errorResponse(errors.Wrap(err, "failed to sync with upstream"))
+	}
+
+	b, err := repo.ReadFile(acmcsuf.OfficersJSONPath)
+	if err != nil {
+		return errorResponse(errors.Wrap(err, "failed to read officers.json"))
 	}
-	defer f.Close()
 
-	var officers acmcsuf.Officers
-	if err := json.NewDecoder(f).Decode(&officers); err != nil {
+	var oldOfficers, newOfficers acmcsuf.Officers
+	if err := json.Unmarshal(b, &oldOfficers); err != nil {
 		return errorResponse(errors.Wrap(err, "failed to decode officers.json"))
 	}
 
-	commit, err := updateFn(&officers)
+	officersFile, err := acmcsuf.DecodeJSONFile(b, &newOfficers)
+	if err != nil {
+		return errorResponse(errors.Wrap(err, "failed to decode officers.json"))
+	}
+
+	commit, err := updateFn(&newOfficers)
 	if err != nil {
 		return errorResponse(err)
 	}
 
-	if err := f.Wipe(); err != nil {
-		return errorResponse(errors.Wrap(err, "failed to override officers.json"))
+	if err := h.validateOfficers(ctx, command.Event, oldOfficers, newOfficers); err != nil {
+		return errorResponse(err)
 	}
 
-	if err := json.NewEncoder(f).Encode(officers); err != nil {
+	changes := acmcsuf.DiffOfficers(oldOfficers, newOfficers)
+	if len(changes) == 0 && len(commit.Files) == 0 {
+		return &api.InteractionResponseData{
+			Content: option.NewNullableString("Nothing to change." + droppedNotice(sync)),
+			Flags:   discord.EphemeralMessage,
+		}
+	}
+
+	newJSON, err := officersFile.Marshal(newOfficers)
+	if err != nil {
 		return errorResponse(errors.Wrap(err, "failed to encode officers.json"))
 	}
 
-	commitHash, err := repo.Commit(commit.Title, commit.Body)
+	id := h.addPendingEdit(command.Event, repo, pendingCommit{
+		commit:   commit,
+		base:     b,
+		officers: newJSON,
+	})
+
+	return &api.InteractionResponseData{
+		Content: option.NewNullableString(fmt.Sprintf(
+			"**%s**\n\n%s\n%s%s\nConfirm to commit these changes.",
+			commit.Title, commit.Body, formatChanges(changes), formatFiles(commit.Files),
+		) + droppedNotice(sync)),
+		Flags:           discord.EphemeralMessage,
+		AllowedMentions: &api.AllowedMentions{ /* none */ },
+		Components: &discord.ContainerComponents{
+			&discord.ActionRowComponent{
+				&discord.ButtonComponent{
+					Label:    "Confirm",
+					CustomID: componentID("edit-confirm", id),
+					Style:    discord.SuccessButtonStyle(),
+				},
+				&discord.ButtonComponent{
+					Label:    "Cancel",
+					CustomID: componentID("edit-cancel", id),
+					Style:    discord.SecondaryButtonStyle(),
+				},
+			},
+		},
+	}
+}
+
+// validateOfficers returns an error if the update from oldOfficers to
+// newOfficers introduced any problems. Problems that were already there are
+// ignored, so that bad upstream data doesn't block every edit.
+func (h *Handler) validateOfficers(ctx context.Context, ev *discord.InteractionEvent, oldOfficers, newOfficers acmcsuf.Officers) error {
+	tiers, err := h.readTiers(ctx, ev)
+	if err != nil {
+		return err
+	}
+
+	newErr := newOfficers.Validate(tiers)
+	if newErr == nil {
+		return nil
+	}
+
+	// Officers may have moved around, so ignore their indices.
+	problemKey := func(err acmcsuf.FieldError) string {
+		return err.Officer + "\x00" + err.Field + "\x00" + err.Err.Error()
+	}
+
+	existing := make(map[string]struct{})
+	if oldErr, ok := oldOfficers.Validate(tiers).(acmcsuf.ValidationError); ok {
+		for _, err := range oldErr {
+			existing[problemKey(err)] = struct{}{}
+		}
+	}
+
+	var introduced acmcsuf.ValidationError
+	for _, err := range newErr.(acmcsuf.ValidationError) {
+		if _, ok := existing[problemKey(err)]; !ok {
+			introduced = append(introduced, err)
+		}
+	}
+
+	if len(introduced) > 0 {
+		return errors.Wrap(introduced, "refusing to make invalid changes")
+	}
+
+	return nil
+}
+
+// formatFiles lists the files that are committed along with officers.json.
+func formatFiles(files map[string][]byte) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var list strings.Builder
+	list.WriteString("\nAlso writes:\n")
+	for _, name := range names {
+		if files[name] == nil {
+			fmt.Fprintf(&list, "- `%s` (removed)\n", path.Clean(name))
+			continue
+		}
+		fmt.Fprintf(&list, "- `%s` (%d KB)\n", path.Clean(name), (len(files[name])+1023)/1024)
+	}
+	return list.String()
+}
+
+// formatChanges formats the changes as a diff code block.
+func formatChanges(changes []acmcsuf.Change) string {
+	var diff strings.Builder
+	diff.WriteString("```diff\n")
+	for _, change := range changes {
+		switch change.Kind() {
+		case "add":
+			diff.WriteString("+ ")
+		case "remove":
+			diff.WriteString("- ")
+		default:
+			diff.WriteString("~ ")
+		}
+		diff.WriteString(strings.ReplaceAll(change.String(), "`", "'"))
+		diff.WriteByte('\n')
+	}
+	diff.WriteString("```")
+	return diff.String()
+}
+
+// pendingCommit is a previewed change to officers.json that is waiting for
+// the user to confirm it.
+type pendingCommit struct {
+	commit commit
+	// base is officers.json as it was previewed against.
+	base []byte
+	// officers is the new officers.json.
+	officers []byte
+}
+
+// commitOfficers commits the previewed changes into the user's workspace. It
+// refuses to if officers.json changed since the preview, since the user would
+// otherwise confirm something that they haven't seen.
+func (h *Handler) commitOfficers(ctx context.Context, ev *discord.InteractionEvent, pending pendingCommit) (string, error) {
+	repo, err := h.initUserWorkspace(ctx, ev)
 	if err != nil {
-		return errorResponse(errors.Wrap(err, "failed to commit"))
+		return "", err
 	}
+	defer repo.Release()
+
+	commit := pending.commit
+
+	commitHash, err := repo.Edit(func(edit *gitwork.Edit) (string, string, error) {
+		b, err := edit.ReadFile(acmcsuf.OfficersJSONPath)
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to read officers.json")
+		}
+
+		if !bytes.Equal(b, pending.base) {
+			return "", "", errors.New(
+				"officers.json changed since the preview, please run the command again")
+		}
+
+		edit.WriteFile(acmcsuf.OfficersJSONPath, pending.officers)
+		for name, data := range commit.Files {
+			if data == nil {
+				edit.Remove(name)
+				continue
+			}
+			edit.WriteFile(name, data)
+		}
 
-	if err := f.Close(); err != nil {
-		return errorResponse(errors.Wrap(err, "failed to close officers.json"))
+		return commit.Title, commit.Body, nil
+	})
+	if err != nil {
+		if errors.Is(err, gitwork.ErrNoChanges) {
+			return "", errors.New("nothing to change, the changes are already committed")
+		}
+		return "", err
 	}
 
 	shortHash := commitHash.String()[:7]
-	return &api.InteractionResponseData{
-		Content: option.NewNullableString(fmt.Sprintf(
-			"`[%s]` %s\n\n%s",
-			shortHash, commit.Title, commit.Body,
-		)),
+	return fmt.Sprintf("`[%s]` %s\n\n%s", shortHash, commit.Title, commit.Body), nil
+}
+
+// droppedNotice returns a notice about the pending changes that had to be
+// dropped or discarded while syncing, or an empty string if there were none.
+func droppedNotice(sync *gitwork.SyncResult) string {
+	if len(sync.Dropped) == 0 && len(sync.Discarded) == 0 {
+		return ""
 	}
+
+	var notice strings.Builder
+
+	if len(sync.Dropped) > 0 {
+		notice.WriteString("\n\n**Warning:** the following pending changes conflicted " +
+			"with newer upstream changes and were dropped:")
+		for _, commit := range sync.Dropped {
+			title, _, _ := strings.Cut(commit.Message, "\n")
+			fmt.Fprintf(&notice, "\n- `[%s]` %s (%v)", commit.Hash.String()[:7], title, commit.Err)
+		}
+	}
+
+	if len(sync.Discarded) > 0 {
+		notice.WriteString("\n\n**Warning:** uncommitted changes to the following files " +
+			"were discarded while syncing:")
+		for _, path := range sync.Discarded {
+			fmt.Fprintf(&notice, "\n- `%s`", path)
+		}
+	}
+
+	return notice.String()
 }
 
+// forUser returns the member that the command edits. If forUser is another
+// user, then the sender must be an editor.
 func (h *Handler) forUser(command cmdroute.CommandData, forUser discord.UserID) (*discord.Member, error) {
-	if (!forUser.IsValid() || forUser == command.Event.User.ID) && command.Event.Member != nil {
+	if !isSender(command.Event, forUser) {
+		if err := h.authorizeEditor(command.Event); err != nil {
+			return nil, err
+		}
+	}
+
+	return h.member(command, forUser)
+}
+
+// isSender returns true if userID is unset or is the sender of the
+// interaction.
+func isSender(ev *discord.InteractionEvent, userID discord.UserID) bool {
+	return !userID.IsValid() || userID == ev.SenderID()
+}
+
+// member returns the given member of the guild, or the sender if userID is
+// unset. Anyone may look up any member, so it's only for commands that don't
+// edit anything; use forUser otherwise.
+func (h *Handler) member(command cmdroute.CommandData, userID discord.UserID) (*discord.Member, error) {
+	if isSender(command.Event, userID) && command.Event.Member != nil {
 		return command.Event.Member, nil
 	}
+	if !userID.IsValid() {
+		return nil, errors.New("this command only works in a server")
+	}
 
-	member, err := h.state.Member(command.Event.GuildID, forUser)
+	member, err := h.state.Member(command.Event.GuildID, userID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get requested user")
 	}
@@ -95,10 +343,49 @@ func (h *Handler) forUser(command cmdroute.CommandData, forUser discord.UserID)
 	return member, nil
 }
 
+// findLinkedOfficer returns the officer that is linked to the given member.
+// Officers that were linked before Discord IDs were recorded are found by
+// their handle. Their Discord fields are left alone, so that a commit only
+// changes what it says it does; /officer link and /officer migrate-ids record
+// the IDs.
+func findLinkedOfficer(officers acmcsuf.Officers, member *discord.Member) (*acmcsuf.Officer, error) {
+	officer, err := officers.FindOne(byDiscordUser(member.User))
+	if err != nil {
+		if errors.Is(err, acmcsuf.ErrOfficerNotFound) {
+			return nil, errors.New("officer not found (have you done /officer link?)")
+		}
+		return nil, err
+	}
+
+	return officer, nil
+}
+
+// byDiscordUser matches the officer that is linked to the given user.
+func byDiscordUser(user discord.User) acmcsuf.Matcher {
+	return acmcsuf.ByDiscordUser(user.ID.String(), discordHandle(user))
+}
+
+// linkDiscord links the officer to the given user, updating the handle in
+// case it has changed.
+func linkDiscord(officer *acmcsuf.Officer, user discord.User) {
+	officer.Socials.Discord = discordHandle(user)
+	officer.Socials.DiscordID = user.ID.String()
+}
+
+// discordHandle returns the handle that the user is displayed with. Users that
+// have migrated off discriminators are displayed with just their username.
+func discordHandle(user discord.User) string {
+	if user.Discriminator == "" || user.Discriminator == "0" {
+		return user.Username
+	}
+	return user.Tag()
+}
+
 func (h *Handler) handleLink(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
 	var data struct {
 		ForUser  discord.UserID `discord:"for_user?"`
 		FullName string         `discord:"full_name"`
+		New      bool           `discord:"new?"`
 	}
 
 	if err := command.Options.Unmarshal(&data); err != nil {
@@ -111,26 +398,62 @@ func (h *Handler) handleLink(ctx context.Context, command cmdroute.CommandData)
 	}
 
 	return h.updateOfficers(ctx, command, func(officers *acmcsuf.Officers) (commit, error) {
-		if officer := officers.Find(func(officer *acmcsuf.Officer) bool {
-			return officer.FullName == data.FullName
-		}); officer != nil {
-			officer.Socials.Discord = member.User.Tag()
+		officer, err := officers.FindOne(acmcsuf.ByName(data.FullName))
+		if err != nil && !errors.Is(err, acmcsuf.ErrOfficerNotFound) {
+			return commit{}, err
+		}
+
+		fullName := data.FullName
+
+		if officer != nil {
+			fullName = officer.FullName
+			// Relinking an officer that belongs to someone else is editing
+			// their record.
+			linked := officer.Socials.Discord != "" || officer.Socials.DiscordID != ""
+			if linked && !byDiscordUser(*command.Event.Sender())(officer) {
+				if err := h.authorizeEditor(command.Event); err != nil {
+					return commit{}, err
+				}
+			}
+			linkDiscord(officer, member.User)
 		} else {
-			*officers = append(*officers, acmcsuf.Officer{
-				FullName: data.FullName,
-				Socials: acmcsuf.Socials{
-					Discord: member.User.Tag(),
-				},
-			})
+			if !data.New {
+				return commit{}, unknownOfficerError(*officers, data.FullName)
+			}
+			*officers = append(*officers, acmcsuf.Officer{FullName: data.FullName})
+			linkDiscord(&(*officers)[len(*officers)-1], member.User)
 		}
 
 		return commit{
-			Title: fmt.Sprintf("Update officer %s", data.FullName),
-			Body:  fmt.Sprintf("Update officer %s's Discord tag to %q.", data.FullName, member.User.Tag()),
+			Title: fmt.Sprintf("Update officer %s", fullName),
+			Body: fmt.Sprintf(
+				"Link officer %s to Discord user %s (%s).",
+				fullName, discordHandle(member.User), member.User.ID,
+			),
 		}, nil
 	})
 }
 
+// unknownOfficerError returns the error for linking to a full name that no
+// officer has, suggesting the closest names in case of a typo.
+func unknownOfficerError(officers acmcsuf.Officers, fullName string) error {
+	names := make([]string, len(officers))
+	for i, officer := range officers {
+		names[i] = officer.FullName
+	}
+
+	msg := fmt.Sprintf("no officer is named %q.", fullName)
+	if similar := fuzzyFilter(fullName, names); len(similar) > 0 {
+		if len(similar) > 3 {
+			similar = similar[:3]
+		}
+		msg = fmt.Sprintf("no officer is named %q, did you mean %s?",
+			fullName, strings.Join(similar, ", "))
+	}
+
+	return errors.New(msg + " Set new to add a new officer.")
+}
+
 func (h *Handler) handleSet(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
 	var data struct {
 		ForUser   discord.UserID `discord:"for_user?"`
@@ -150,47 +473,411 @@ func (h *Handler) handleSet(ctx context.Context, command cmdroute.CommandData) *
 	}
 
 	return h.updateOfficers(ctx, command, func(officers *acmcsuf.Officers) (commit, error) {
-		officer := officers.Find(func(officer *acmcsuf.Officer) bool {
-			return officer.Socials.Discord == member.User.Tag()
-		})
+		officer, err := findLinkedOfficer(*officers, member)
+		if err != nil {
+			return commit{}, err
+		}
 
-		if officer == nil {
-			return commit{}, errors.New("officer not found (have you done /officer link?)")
+		values := map[string]string{
+			"github":    data.GitHub,
+			"linkedin":  data.LinkedIn,
+			"instagram": data.Instagram,
+			"website":   data.Website,
 		}
 
-		var updated []string
-		if data.GitHub != "" {
-			officer.Socials.GitHub = data.GitHub
-			updated = append(updated, "GitHub")
+		var body strings.Builder
+		fmt.Fprintf(&body, "Update officer %s's socials:\n", officer.FullName)
+
+		var updated int
+		for _, platform := range acmcsuf.SocialPlatforms {
+			value := values[platform.Key]
+			if value == "" {
+				continue
+			}
+
+			if err := officer.Socials.Set(platform.Key, value); err != nil {
+				return commit{}, err
+			}
+
+			fmt.Fprintf(&body, "- %s: %s\n", platform.Name, officer.Socials.Get(platform.Key))
+			updated++
 		}
-		if data.LinkedIn != "" {
-			officer.Socials.LinkedIn = data.LinkedIn
-			updated = append(updated, "LinkedIn")
+
+		if updated == 0 {
+			return commit{}, errors.New("nothing to set, give at least one social")
 		}
-		if data.Instagram != "" {
-			officer.Socials.Instagram = data.Instagram
-			updated = append(updated, "Instagram")
+
+		return commit{
+			Title: fmt.Sprintf("Update officer %s", officer.FullName),
+			Body:  strings.TrimSuffix(body.String(), "\n"),
+		}, nil
+	})
+}
+
+func (h *Handler) handleUnset(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
+	var data struct {
+		ForUser   discord.UserID `discord:"for_user?"`
+		GitHub    bool           `discord:"github?"`
+		LinkedIn  bool           `discord:"linkedin?"`
+		Instagram bool           `discord:"instagram?"`
+		Website   bool           `discord:"website?"`
+		Field     string         `discord:"field?"`
+	}
+
+	if err := command.Options.Unmarshal(&data); err != nil {
+		return errorResponse(err)
+	}
+
+	member, err := h.forUser(command, data.ForUser)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return h.updateOfficers(ctx, command, func(officers *acmcsuf.Officers) (commit, error) {
+		officer, err := findLinkedOfficer(*officers, member)
+		if err != nil {
+			return commit{}, err
 		}
-		if data.Website != "" {
-			officer.Socials.Website = data.Website
-			updated = append(updated, "Website")
+
+		clear := map[string]bool{
+			"github":    data.GitHub,
+			"linkedin":  data.LinkedIn,
+			"instagram": data.Instagram,
+			"website":   data.Website,
 		}
 
+		var cleared []string
+		for _, platform := range acmcsuf.SocialPlatforms {
+			if !clear[platform.Key] {
+				continue
+			}
+
+			old := officer.Socials.Get(platform.Key)
+			if old == "" {
+				continue
+			}
+
+			if err := officer.Socials.Set(platform.Key, ""); err != nil {
+				return commit{}, err
+			}
+
+			cleared = append(cleared, fmt.Sprintf("%s (was %s)", platform.Name, old))
+		}
+
+		if data.Field != "" {
+			// Fields are either "key" for the officer or "socials.key" for a
+			// social platform, same as /officer set-field.
+			extra := &officer.Extra
+			key := data.Field
+			if strings.HasPrefix(key, "socials.") {
+				extra = &officer.Socials.Extra
+				key = strings.TrimPrefix(key, "socials.")
+			}
+
+			old, ok := extra.String(key)
+			if !ok {
+				return commit{}, fmt.Errorf("officer %s has no field %s", officer.FullName, data.Field)
+			}
+
+			extra.Delete(key)
+			cleared = append(cleared, fmt.Sprintf("%s (was %s)", data.Field, old))
+		}
+
+		if len(cleared) == 0 {
+			return commit{}, errors.New("nothing to clear, the chosen fields are already empty")
+		}
+
+		var body strings.Builder
+		fmt.Fprintf(&body, "Clear officer %s's fields:", officer.FullName)
+		for _, field := range cleared {
+			fmt.Fprintf(&body, "\n- %s", field)
+		}
+
+		return commit{
+			Title: fmt.Sprintf("Update officer %s", officer.FullName),
+			Body:  body.String(),
+		}, nil
+	})
+}
+
+func (h *Handler) handleSetField(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
+	var data struct {
+		ForUser discord.UserID `discord:"for_user?"`
+		Field   string         `discord:"field"`
+		Value   string         `discord:"value"`
+	}
+
+	if err := command.Options.Unmarshal(&data); err != nil {
+		return errorResponse(err)
+	}
+
+	member, err := h.forUser(command, data.ForUser)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	// Fields are either "key" for the officer or "socials.key" for a social
+	// platform.
+	isSocial := strings.HasPrefix(data.Field, "socials.")
+	key := strings.TrimPrefix(data.Field, "socials.")
+	if key == "" {
+		return errorResponse(errors.New("field name is empty"))
+	}
+
+	return h.updateOfficers(ctx, command, func(officers *acmcsuf.Officers) (commit, error) {
+		officer, err := findLinkedOfficer(*officers, member)
+		if err != nil {
+			return commit{}, err
+		}
+
+		extra := &officer.Extra
+		known := acmcsuf.IsKnownField(officer, key)
+		if isSocial {
+			extra = &officer.Socials.Extra
+			known = acmcsuf.IsKnownField(officer.Socials, key)
+		}
+
+		if known {
+			return commit{}, fmt.Errorf("%s is a known field, use /officer set or /officer link instead", data.Field)
+		}
+
+		extra.SetString(key, data.Value)
+
 		return commit{
-			Title: fmt.Sprintf("Update officer %s", member.User.Username),
+			Title: fmt.Sprintf("Update officer %s", officer.FullName),
+			Body:  fmt.Sprintf("Set officer %s's %s to %q.", officer.FullName, data.Field, data.Value),
+		}, nil
+	})
+}
+
+func (h *Handler) handleAddTerm(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
+	var data struct {
+		ForUser discord.UserID `discord:"for_user?"`
+		Title   string         `discord:"title"`
+		Term    string         `discord:"term?"`
+	}
+
+	if err := command.Options.Unmarshal(&data); err != nil {
+		return errorResponse(err)
+	}
+
+	member, err := h.forUser(command, data.ForUser)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	term, err := h.termOrCurrent(data.Term)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return h.updateOfficers(ctx, command, func(officers *acmcsuf.Officers) (commit, error) {
+		officer, err := findLinkedOfficer(*officers, member)
+		if err != nil {
+			return commit{}, err
+		}
+
+		tiers, err := h.readTiers(ctx, command.Event)
+		if err != nil {
+			return commit{}, err
+		}
+
+		officerTerm, err := tiers.NewOfficerTerm(data.Title)
+		if err != nil {
+			return commit{}, err
+		}
+
+		if officer.Terms == nil {
+			officer.Terms = make(map[acmcsuf.Term]acmcsuf.OfficerTerm)
+		}
+
+		officer.Terms[term] = officerTerm
+
+		return commit{
+			Title: fmt.Sprintf("Update officer %s", officer.FullName),
 			Body: fmt.Sprintf(
-				"Update officer %s's socials (%s).",
-				member.User.Username, strings.Join(updated, ", "),
+				"Add term %s %d for officer %s as %s.",
+				term.Semester(), term.Year(), officer.FullName, officerTerm.Title,
 			),
 		}, nil
 	})
-	panic("TODO")
 }
 
-func (h *Handler) handleAddTerm(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
-	panic("TODO")
+// readTiers reads the tiers.json file from the user's workspace.
+func (h *Handler) readTiers(ctx context.Context, ev *discord.InteractionEvent) (acmcsuf.Tiers, error) {
+	repo, err := h.initUserWorkspace(ctx, ev)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Release()
+
+	f, err := repo.OpenFile(acmcsuf.TiersJSONPath, os.O_RDONLY)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open tiers.json")
+	}
+	defer f.Close()
+
+	tiers, err := acmcsuf.LoadTiers(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load tiers.json")
+	}
+
+	return tiers, nil
+}
+
+// termOrCurrent parses the given term. If it's empty, then the current term
+// is used.
+func (h *Handler) termOrCurrent(term string) (acmcsuf.Term, error) {
+	if term == "" {
+		return acmcsuf.CurrentTerm(h.Calendar, time.Now()), nil
+	}
+	return acmcsuf.ParseTerm(term)
 }
 
 func (h *Handler) handlePR(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
-	panic("TODO")
+	if h.forge == nil {
+		return errorResponse(errors.New("pull requests are not configured for this bot"))
+	}
+
+	repo, err := h.initUserWorkspace(ctx, command.Event)
+	if err != nil {
+		return errorResponse(err)
+	}
+	defer repo.Release()
+
+	head, err := repo.Head()
+	if err != nil {
+		return errorResponse(errors.Wrap(err, "failed to get workspace HEAD"))
+	}
+
+	base, err := repo.UpstreamBranch()
+	if err != nil {
+		// Branches not created by the pool may not track anything, so ask
+		// the forge instead.
+		base, err = h.forge.DefaultBranch(ctx)
+		if err != nil {
+			return errorResponse(errors.Wrap(err, "failed to get default branch"))
+		}
+	}
+
+	branch := head.Name().Short()
+	if branch == base {
+		return errorResponse(fmt.Errorf("workspace is on the default branch %q, refusing to push", base))
+	}
+
+	// Decide on the trees rather than the commits, since commits that were
+	// undone or already merged upstream leave nothing to submit.
+	changed, err := repo.ChangedFiles(base)
+	if err != nil {
+		return errorResponse(errors.Wrap(err, "failed to list changes"))
+	}
+
+	if len(changed) == 0 {
+		return errorResponse(errors.New("nothing to submit, your workspace has no changes"))
+	}
+
+	commits, err := repo.CommitsAhead(base)
+	if err != nil {
+		return errorResponse(errors.Wrap(err, "failed to list changes"))
+	}
+
+	// Syncing may have replayed commits that were already pushed, so the
+	// branch can need a force push, but never over someone else's commits.
+	if err := repo.PushWithLease(ctx); err != nil {
+		if errors.Is(err, gitwork.ErrStaleLease) {
+			return errorResponse(errors.New(
+				"someone else pushed to your PR branch, so your changes were not pushed " +
+					"to avoid overwriting theirs"))
+		}
+		return errorResponse(errors.Wrap(err, "failed to push changes"))
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Changes requested by %s on Discord.\n\n", discordHandle(*command.Event.Sender()))
+	for i := len(commits) - 1; i >= 0; i-- {
+		title, _, _ := strings.Cut(commits[i].Message, "\n")
+		fmt.Fprintf(&body, "- %s\n", title)
+	}
+
+	opts := forge.PullRequestOptions{
+		Title: fmt.Sprintf("Update officer data from %s", command.Event.Sender().Username),
+		Body:  body.String(),
+		Head:  branch,
+		Base:  base,
+	}
+
+	pr, err := h.findPR(ctx, repo, branch)
+	if err != nil {
+		return errorResponse(errors.Wrap(err, "failed to find existing PR"))
+	}
+
+	verb := "Updated"
+	if pr != nil {
+		pr, err = h.forge.UpdatePullRequest(ctx, pr.Number, opts)
+	} else {
+		verb = "Created"
+		pr, err = h.forge.CreatePullRequest(ctx, opts)
+	}
+	if err != nil {
+		return errorResponse(errors.Wrap(err, "failed to submit PR"))
+	}
+
+	repo.SetPullRequest(pr.Number)
+
+	return &api.InteractionResponseData{
+		Content: option.NewNullableString(fmt.Sprintf(
+			"%s PR [#%d](%s) changing %d file(s).",
+			verb, pr.Number, pr.URL, len(changed),
+		)),
+	}
+}
+
+// findPR finds the ongoing PR of the given workspace. If the workspace has no
+// open PR, then nil is returned.
+func (h *Handler) findPR(ctx context.Context, repo *gitwork.PooledRepository, branch string) (*forge.PullRequest, error) {
+	if number := repo.Info().PullRequest; number != 0 {
+		pr, err := h.forge.PullRequest(ctx, number)
+		if err == nil && pr.Open {
+			return pr, nil
+		}
+		if err != nil && err != forge.ErrNotFound {
+			return nil, err
+		}
+	}
+
+	// Either we don't know about the PR (e.g. it was made by hand) or the PR
+	// has been closed, so look it up by its branch instead.
+	pr, err := h.forge.FindPullRequest(ctx, branch)
+	if err != nil {
+		if err == forge.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+// KeepWorkspace reports whether the workspace still has an open pull request,
+// in which case it must not be evicted. It is meant to be used as
+// gitwork.Pool.Keep.
+func (h *Handler) KeepWorkspace(ctx context.Context, info gitwork.WorkspaceInfo) (bool, error) {
+	if info.PullRequest == 0 {
+		return false, nil
+	}
+
+	if h.forge == nil {
+		// We can't tell, so play it safe.
+		return true, nil
+	}
+
+	pr, err := h.forge.PullRequest(ctx, info.PullRequest)
+	if err != nil {
+		if errors.Is(err, forge.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return pr.Open, nil
 }
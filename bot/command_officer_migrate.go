@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/api/cmdroute"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/httputil"
+	"github.com/diamondburned/officer-data/acmcsuf"
+	"github.com/pkg/errors"
+)
+
+// handleMigrateIDs backfills the Discord IDs of officers that were linked
+// before IDs were recorded by matching their handles against the guild's
+// members. Listing members requires the Server Members intent to be enabled
+// for the bot.
+func (h *Handler) handleMigrateIDs(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
+	if err := h.authorizeEditor(command.Event); err != nil {
+		return errorResponse(err)
+	}
+
+	members, err := h.state.Client.WithContext(ctx).Members(command.Event.GuildID, 0)
+	if err != nil {
+		var httpErr *httputil.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Status == 403 {
+			return errorResponse(errors.New("cannot list guild members: " +
+				"enable the Server Members intent for the bot in the Discord developer portal"))
+		}
+		return errorResponse(errors.Wrap(err, "failed to list guild members"))
+	}
+
+	return h.updateOfficers(ctx, command, func(officers *acmcsuf.Officers) (commit, error) {
+		var linked, unmatched []string
+
+		for i := range *officers {
+			officer := &(*officers)[i]
+			if officer.Socials.DiscordID != "" || officer.Socials.Discord == "" {
+				continue
+			}
+
+			user, err := matchMember(members, officer.Socials.Discord)
+			if err != nil {
+				unmatched = append(unmatched, fmt.Sprintf("%s (%v)", officer.FullName, err))
+				continue
+			}
+
+			if other := officers.Find(acmcsuf.ByDiscordID(user.ID.String())); other != nil {
+				unmatched = append(unmatched, fmt.Sprintf(
+					"%s (%s is already linked to %s)",
+					officer.FullName, discordHandle(*user), other.FullName,
+				))
+				continue
+			}
+
+			linkDiscord(officer, *user)
+			linked = append(linked, fmt.Sprintf("%s as %s (%s)", officer.FullName, officer.Socials.Discord, user.ID))
+		}
+
+		if len(linked) == 0 {
+			if len(unmatched) == 0 {
+				return commit{}, errors.New("all linked officers already have Discord IDs")
+			}
+			return commit{}, fmt.Errorf(
+				"no officers could be matched to a guild member:\n- %s",
+				strings.Join(unmatched, "\n- "),
+			)
+		}
+
+		var body strings.Builder
+		body.WriteString("Record the Discord IDs of these officers:\n")
+		for _, officer := range linked {
+			fmt.Fprintf(&body, "- %s\n", officer)
+		}
+		if len(unmatched) > 0 {
+			body.WriteString("\nThese officers could not be matched:\n")
+			for _, officer := range unmatched {
+				fmt.Fprintf(&body, "- %s\n", officer)
+			}
+		}
+
+		return commit{
+			Title: fmt.Sprintf("Record Discord IDs of %d officers", len(linked)),
+			Body:  strings.TrimSuffix(body.String(), "\n"),
+		}, nil
+	})
+}
+
+// matchMember returns the user among the members that has the given handle.
+// Handles with a discriminator also match users that have since migrated off
+// discriminators and kept their username.
+func matchMember(members []discord.Member, handle string) (*discord.User, error) {
+	username, _, hasDiscriminator := strings.Cut(handle, "#")
+
+	var exact, migrated []*discord.User
+	for i := range members {
+		user := &members[i].User
+		switch {
+		case strings.EqualFold(handle, user.Tag()), strings.EqualFold(handle, discordHandle(*user)):
+			exact = append(exact, user)
+		case hasDiscriminator && strings.EqualFold(username, discordHandle(*user)):
+			migrated = append(migrated, user)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = migrated
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no member named %s", handle)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%d members named %s", len(matches), handle)
+	}
+}
@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/api/cmdroute"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/officer-data/acmcsuf"
+	"github.com/pkg/errors"
+)
+
+func (h *Handler) handlePicture(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
+	var data struct {
+		ForUser discord.UserID `discord:"for_user?"`
+	}
+
+	if err := command.Options.Unmarshal(&data); err != nil {
+		return errorResponse(err)
+	}
+
+	member, err := h.forUser(command, data.ForUser)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	attachment, err := commandAttachment(command, "picture")
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	picture, err := downloadPicture(ctx, attachment)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return h.updateOfficers(ctx, command, func(officers *acmcsuf.Officers) (commit, error) {
+		officer, err := findLinkedOfficer(*officers, member)
+		if err != nil {
+			return commit{}, err
+		}
+
+		oldPicture := officer.Picture
+		officer.Picture = acmcsuf.PictureName(officer.FullName)
+
+		files := map[string][]byte{
+			acmcsuf.PicturePath(officer.Picture): picture,
+		}
+		body := fmt.Sprintf("Update officer %s's picture.", officer.FullName)
+
+		// Remove the picture that is being replaced, unless someone else
+		// still uses it.
+		if oldPicture != officer.Picture && isPictureName(oldPicture) &&
+			officers.Find(usesPicture(oldPicture)) == nil {
+
+			files[acmcsuf.PicturePath(oldPicture)] = nil
+			body += fmt.Sprintf(" Remove the old picture %s.", oldPicture)
+		}
+
+		return commit{
+			Title: fmt.Sprintf("Update officer %s", officer.FullName),
+			Body:  body,
+			Files: files,
+		}, nil
+	})
+}
+
+// isPictureName returns true if name is the name of a file directly inside
+// acmcsuf.PicturesPath, so that removing it can't touch anything else.
+func isPictureName(name string) bool {
+	return name != "" && name != "." && name != ".." && path.Base(name) == name
+}
+
+// usesPicture matches officers whose picture is the given file.
+func usesPicture(name string) acmcsuf.Matcher {
+	return func(officer *acmcsuf.Officer) bool {
+		return officer.Picture == name
+	}
+}
+
+// commandAttachment returns the attachment given as the option with the given
+// name.
+func commandAttachment(command cmdroute.CommandData, name string) (*discord.Attachment, error) {
+	id, err := command.Options.Find(name).SnowflakeValue()
+	if err != nil {
+		return nil, fmt.Errorf("missing attachment %s", name)
+	}
+
+	interaction, ok := command.Event.Data.(*discord.CommandInteraction)
+	if !ok {
+		return nil, errors.New("interaction is not a command")
+	}
+
+	attachment, ok := interaction.Resolved.Attachments[discord.AttachmentID(id)]
+	if !ok {
+		return nil, fmt.Errorf("attachment %s was not sent with the command", name)
+	}
+
+	return &attachment, nil
+}
+
+// downloadPicture downloads the attachment and processes it into an officer
+// picture.
+func downloadPicture(ctx context.Context, attachment *discord.Attachment) ([]byte, error) {
+	if attachment.ContentType != "" && !strings.HasPrefix(attachment.ContentType, "image/") {
+		return nil, fmt.Errorf("%s is not an image", attachment.Filename)
+	}
+
+	if attachment.Size > acmcsuf.MaxPictureBytes {
+		return nil, fmt.Errorf("%s is larger than %d MB", attachment.Filename, acmcsuf.MaxPictureBytes>>20)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", attachment.URL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download picture")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download picture: unexpected status %s", resp.Status)
+	}
+
+	picture, err := acmcsuf.ProcessPicture(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot use %s", attachment.Filename)
+	}
+
+	return picture, nil
+}
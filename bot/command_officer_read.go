@@ -0,0 +1,341 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/api/cmdroute"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/officer-data/acmcsuf"
+	"github.com/pkg/errors"
+)
+
+// listPageSize is the number of officers shown per page in /officer list.
+const listPageSize = 10
+
+// readOfficers reads officers.json either from the upstream default branch or
+// from the user's workspace branch.
+func (h *Handler) readOfficers(ctx context.Context, ev *discord.InteractionEvent, fromWorkspace bool) (acmcsuf.Officers, error) {
+	if !fromWorkspace {
+		upstream, err := h.upstream(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return upstream.Officers, nil
+	}
+
+	repo, err := h.initUserWorkspace(ctx, ev)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Release()
+
+	b, err := repo.ReadFile(acmcsuf.OfficersJSONPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read officers.json")
+	}
+
+	var officers acmcsuf.Officers
+	if err := json.Unmarshal(b, &officers); err != nil {
+		return nil, errors.Wrap(err, "failed to decode officers.json")
+	}
+
+	return officers, nil
+}
+
+func (h *Handler) handleShow(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
+	var data struct {
+		User      discord.UserID `discord:"user?"`
+		FullName  string         `discord:"full_name?"`
+		Workspace bool           `discord:"workspace?"`
+	}
+
+	if err := command.Options.Unmarshal(&data); err != nil {
+		return errorResponse(err)
+	}
+
+	officers, err := h.readOfficers(ctx, command.Event, data.Workspace)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	var officer *acmcsuf.Officer
+	if data.FullName != "" {
+		officer, err = officers.FindOne(acmcsuf.ByName(data.FullName))
+		if err != nil {
+			if errors.Is(err, acmcsuf.ErrOfficerNotFound) {
+				return errorResponse(fmt.Errorf("no officer named %q", data.FullName))
+			}
+			return errorResponse(err)
+		}
+	} else {
+		member, err := h.member(command, data.User)
+		if err != nil {
+			return errorResponse(err)
+		}
+
+		officer, err = officers.FindOne(byDiscordUser(member.User))
+		if err != nil {
+			if errors.Is(err, acmcsuf.ErrOfficerNotFound) {
+				return errorResponse(fmt.Errorf("%s is not linked to any officer", discordHandle(member.User)))
+			}
+			return errorResponse(err)
+		}
+	}
+
+	return &api.InteractionResponseData{
+		Embeds: &[]discord.Embed{officerEmbed(officer)},
+	}
+}
+
+func officerEmbed(officer *acmcsuf.Officer) discord.Embed {
+	embed := discord.Embed{
+		Title: officer.FullName,
+	}
+
+	if strings.HasPrefix(officer.Picture, "https://") {
+		embed.Thumbnail = &discord.EmbedThumbnail{URL: officer.Picture}
+	} else if officer.Picture != "" {
+		embed.Fields = append(embed.Fields, discord.EmbedField{
+			Name:  "Picture",
+			Value: officer.Picture,
+		})
+	}
+
+	socials := []struct {
+		name  string
+		value string
+	}{
+		{"Discord", discordField(officer.Socials)},
+	}
+
+	for _, platform := range acmcsuf.SocialPlatforms {
+		value := officer.Socials.Get(platform.Key)
+		if url := officer.Socials.URL(platform.Key); url != "" && url != value {
+			value = fmt.Sprintf("[%s](%s)", value, url)
+		}
+		socials = append(socials, struct {
+			name  string
+			value string
+		}{platform.Name, value})
+	}
+
+	for _, key := range officer.Socials.Extra.Keys() {
+		value, _ := officer.Socials.Extra.String(key)
+		socials = append(socials, struct {
+			name  string
+			value string
+		}{key, value})
+	}
+
+	for _, social := range socials {
+		if social.value != "" {
+			embed.Fields = append(embed.Fields, discord.EmbedField{
+				Name:   social.name,
+				Value:  social.value,
+				Inline: true,
+			})
+		}
+	}
+
+	for _, key := range officer.Extra.Keys() {
+		value, _ := officer.Extra.String(key)
+		embed.Fields = append(embed.Fields, discord.EmbedField{
+			Name:  key,
+			Value: value,
+		})
+	}
+
+	if len(officer.Terms) > 0 {
+		terms := make([]acmcsuf.Term, 0, len(officer.Terms))
+		for term := range officer.Terms {
+			terms = append(terms, term)
+		}
+		acmcsuf.SortTerms(terms)
+
+		var lines strings.Builder
+		for _, term := range terms {
+			fmt.Fprintf(&lines, "%s %d: %s\n", term.Semester(), term.Year(), officer.Terms[term].Title)
+		}
+
+		embed.Fields = append(embed.Fields, discord.EmbedField{
+			Name:  "Terms",
+			Value: lines.String(),
+		})
+	}
+
+	return embed
+}
+
+// discordField formats the officer's Discord handle, mentioning the user if
+// their ID is known.
+func discordField(socials acmcsuf.Socials) string {
+	if socials.DiscordID == "" {
+		return socials.Discord
+	}
+	if socials.Discord == "" {
+		return "<@" + socials.DiscordID + ">"
+	}
+	return fmt.Sprintf("%s (<@%s>)", socials.Discord, socials.DiscordID)
+}
+
+func (h *Handler) handleList(ctx context.Context, command cmdroute.CommandData) *api.InteractionResponseData {
+	var data struct {
+		Term      string `discord:"term?"`
+		Workspace bool   `discord:"workspace?"`
+	}
+
+	if err := command.Options.Unmarshal(&data); err != nil {
+		return errorResponse(err)
+	}
+
+	term, err := h.termOrCurrent(data.Term)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	resp, err := h.listOfficers(ctx, command.Event, listPage{
+		term:      term,
+		workspace: data.Workspace,
+	})
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return resp
+}
+
+// listPage describes a page of /officer list. It is encoded into the
+// pagination buttons.
+type listPage struct {
+	term      acmcsuf.Term
+	page      int
+	workspace bool
+}
+
+func (p listPage) encode() string {
+	source := "u"
+	if p.workspace {
+		source = "w"
+	}
+	return fmt.Sprintf("%s:%s:%d", source, p.term, p.page)
+}
+
+func parseListPage(s string) (listPage, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return listPage{}, fmt.Errorf("invalid list page %q", s)
+	}
+
+	page, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return listPage{}, errors.Wrap(err, "invalid page number")
+	}
+
+	return listPage{
+		term:      acmcsuf.Term(parts[1]),
+		page:      page,
+		workspace: parts[0] == "w",
+	}, nil
+}
+
+func (h *Handler) listOfficers(ctx context.Context, ev *discord.InteractionEvent, page listPage) (*api.InteractionResponseData, error) {
+	officers, err := h.readOfficers(ctx, ev, page.workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	type termOfficer struct {
+		name string
+		term acmcsuf.OfficerTerm
+	}
+
+	var termOfficers []termOfficer
+	for _, officer := range officers.FindAll(acmcsuf.ActiveIn(page.term)) {
+		termOfficers = append(termOfficers, termOfficer{officer.FullName, officer.Terms[page.term]})
+	}
+
+	sort.SliceStable(termOfficers, func(i, j int) bool {
+		if termOfficers[i].term.Tier != termOfficers[j].term.Tier {
+			return termOfficers[i].term.Tier < termOfficers[j].term.Tier
+		}
+		return termOfficers[i].name < termOfficers[j].name
+	})
+
+	pages := (len(termOfficers) + listPageSize - 1) / listPageSize
+	if pages == 0 {
+		pages = 1
+	}
+	if page.page < 0 || page.page >= pages {
+		page.page = 0
+	}
+
+	start := page.page * listPageSize
+	end := start + listPageSize
+	if end > len(termOfficers) {
+		end = len(termOfficers)
+	}
+
+	var desc strings.Builder
+	for _, officer := range termOfficers[start:end] {
+		fmt.Fprintf(&desc, "**%s** — %s\n", officer.term.Title, officer.name)
+	}
+	if len(termOfficers) == 0 {
+		desc.WriteString("No officers found for this term.")
+	}
+
+	embed := discord.Embed{
+		Title:       fmt.Sprintf("Officers of %s %d", page.term.Semester(), page.term.Year()),
+		Description: desc.String(),
+		Footer: &discord.EmbedFooter{
+			Text: fmt.Sprintf("Page %d of %d", page.page+1, pages),
+		},
+	}
+
+	prev := page
+	prev.page--
+	next := page
+	next.page++
+
+	return &api.InteractionResponseData{
+		Embeds: &[]discord.Embed{embed},
+		Components: &discord.ContainerComponents{
+			&discord.ActionRowComponent{
+				&discord.ButtonComponent{
+					Label:    "Previous",
+					CustomID: componentID("officer-list", prev.encode()),
+					Style:    discord.SecondaryButtonStyle(),
+					Disabled: page.page == 0,
+				},
+				&discord.ButtonComponent{
+					Label:    "Next",
+					CustomID: componentID("officer-list", next.encode()),
+					Style:    discord.SecondaryButtonStyle(),
+					Disabled: page.page >= pages-1,
+				},
+			},
+		},
+	}, nil
+}
+
+func (h *Handler) handleListPage(ctx context.Context, ev *discord.InteractionEvent, arg string) *api.InteractionResponse {
+	page, err := parseListPage(arg)
+	if err != nil {
+		return updateMessage("**Error:** " + err.Error())
+	}
+
+	data, err := h.listOfficers(ctx, ev, page)
+	if err != nil {
+		return updateMessage("**Error:** " + err.Error())
+	}
+
+	return &api.InteractionResponse{
+		Type: api.UpdateMessage,
+		Data: data,
+	}
+}
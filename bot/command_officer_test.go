@@ -0,0 +1,198 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/api/cmdroute"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/officer-data/acmcsuf"
+	"github.com/diamondburned/officer-data/internal/gitwork"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+
+	gitconfig "github.com/go-git/go-git/v5/config"
+)
+
+const testOfficersJSON = `[
+  {
+    "fullName": "Ada",
+    "picture": "",
+    "socials": { "discordId": "2" },
+    "terms": { "F22": { "title": "President", "tier": 0 } }
+  }
+]
+`
+
+const testTiersJSON = `["President", "Advisor"]
+`
+
+// newTestHandler creates a handler whose workspaces are kept in memory and
+// cloned from a bare repository holding the given officers.json and
+// tiers.json.
+func newTestHandler(t *testing.T, officersJSON, tiersJSON string) *Handler {
+	t.Helper()
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if _, err := git.PlainInit(remote, true); err != nil {
+		t.Fatal("cannot init remote:", err)
+	}
+
+	repo, err := gitwork.Init(gitwork.InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string]string{
+		acmcsuf.OfficersJSONPath: officersJSON,
+		acmcsuf.TiersJSONPath:    tiersJSON,
+	} {
+		name = path.Clean(name)
+		if err := util.WriteFile(repo.FS(), name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := repo.Commit("Initial commit", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{remote}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Repository.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{"refs/heads/master:refs/heads/master"},
+	})
+	if err != nil {
+		t.Fatal("cannot push to remote:", err)
+	}
+
+	pool, err := gitwork.NewPool(t.TempDir(), remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Store = func(string) gitwork.RepositoryStore { return gitwork.InMemory() }
+	t.Cleanup(func() {
+		if err := pool.Flush(); err != nil {
+			t.Error("Flush:", err)
+		}
+	})
+
+	return New(state.New("Bot test"), pool, nil)
+}
+
+func newTestEvent(guildID discord.GuildID, user discord.User) *discord.InteractionEvent {
+	return &discord.InteractionEvent{
+		GuildID: guildID,
+		Member:  &discord.Member{User: user},
+	}
+}
+
+func TestUpdateOfficersConfirm(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t, testOfficersJSON, testTiersJSON)
+
+	ev := newTestEvent(1, discord.User{ID: 2, Username: "ada"})
+	command := cmdroute.CommandData{Event: ev}
+
+	resp := h.updateOfficers(ctx, command, func(officers *acmcsuf.Officers) (commit, error) {
+		(*officers)[0].Socials.GitHub = "ada"
+		return commit{Title: "Set Ada's GitHub", Body: "Set it to ada."}, nil
+	})
+
+	content := resp.Content.Val
+	if !strings.Contains(content, `Ada: set socials.github to "ada"`) {
+		t.Fatalf("preview doesn't show the change:\n%s", content)
+	}
+	if resp.Components == nil {
+		t.Fatalf("preview has no buttons:\n%s", content)
+	}
+
+	var confirmID string
+	for _, component := range *resp.Components {
+		for _, button := range *component.(*discord.ActionRowComponent) {
+			id := string(button.(*discord.ButtonComponent).CustomID)
+			if strings.HasPrefix(id, "edit-confirm:") {
+				confirmID = strings.TrimPrefix(id, "edit-confirm:")
+			}
+		}
+	}
+	if confirmID == "" {
+		t.Fatal("preview has no confirm button")
+	}
+
+	// Nothing is committed until the edit is confirmed.
+	repo, err := h.gits.Open("1/2")
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	ahead, err := repo.CommitsAhead("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ahead) != 0 {
+		t.Fatalf("%d commits were made before confirming", len(ahead))
+	}
+
+	confirm := h.handleEditConfirm(ctx, ev, confirmID)
+	if confirm.Data == nil || !strings.Contains(confirm.Data.Content.Val, "Set Ada's GitHub") {
+		t.Fatalf("confirm response = %#v, want the commit", confirm.Data)
+	}
+
+	ahead, err = repo.CommitsAhead("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ahead) != 1 || !strings.HasPrefix(ahead[0].Message, "Set Ada's GitHub\n\nSet it to ada.") {
+		t.Fatalf("commits ahead = %v, want the confirmed edit", ahead)
+	}
+
+	b, err := repo.ReadFile(acmcsuf.OfficersJSONPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var officers acmcsuf.Officers
+	if err := json.Unmarshal(b, &officers); err != nil {
+		t.Fatal(err)
+	}
+	if got := officers[0].Socials.GitHub; got != "ada" {
+		t.Errorf("committed GitHub = %q, want ada", got)
+	}
+
+	// The edit can only be confirmed once.
+	again := h.handleEditConfirm(ctx, ev, confirmID)
+	if again.Data == nil || !strings.Contains(again.Data.Content.Val, "expired") {
+		t.Errorf("second confirm response = %#v, want an error", again.Data)
+	}
+}
+
+func TestUpdateOfficersInvalid(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t, testOfficersJSON, testTiersJSON)
+
+	ev := newTestEvent(1, discord.User{ID: 2, Username: "ada"})
+	command := cmdroute.CommandData{Event: ev}
+
+	resp := h.updateOfficers(ctx, command, func(officers *acmcsuf.Officers) (commit, error) {
+		(*officers)[0].Terms["S23"] = acmcsuf.OfficerTerm{Title: "Treasurer", Tier: 2}
+		return commit{Title: "Add a term"}, nil
+	})
+
+	content := resp.Content.Val
+	if !strings.Contains(content, "refusing to make invalid changes") {
+		t.Errorf("response = %q, want a validation error", content)
+	}
+	if resp.Components != nil {
+		t.Error("an invalid edit can be confirmed")
+	}
+}
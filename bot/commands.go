@@ -11,7 +11,13 @@ var commands = []api.CreateCommandData{
 		Description: "Obtain or modify information about an officer.",
 		// /officer link name:"Diamond"              // match name, add a Discord username
 		// /officer set instagram="<instagram name>" // set the instagram name
+		// /officer unset instagram:true             // clear the instagram name
+		// /officer add-term title:"President"       // add a term for the current semester
+		// /officer picture picture:<attachment>     // upload a picture
 		// /officer pr                               // commit to a new PR or update an existing PR
+		// /officer show user:@diamond               // show an officer's information
+		// /officer list term:F22                    // list the officers of a term
+		// /officer migrate-ids                      // record Discord IDs of linked officers
 		Options: []discord.CommandOption{
 			&discord.SubcommandOption{
 				OptionName: "link",
@@ -24,9 +30,15 @@ var commands = []api.CreateCommandData{
 							"If not specified, then the current user is used.",
 					},
 					&discord.StringOption{
-						OptionName:  "full_name",
-						Description: "Your full name or the other user's full name.",
-						Required:    true,
+						OptionName:   "full_name",
+						Description:  "Your full name or the other user's full name.",
+						Required:     true,
+						Autocomplete: true,
+					},
+					&discord.BooleanOption{
+						OptionName: "new",
+						Description: "Add a new officer if no officer has the full name " +
+							"exactly. Leave unset to catch typos.",
 					},
 				},
 			},
@@ -41,19 +53,75 @@ var commands = []api.CreateCommandData{
 					},
 					&discord.StringOption{
 						OptionName:  "github",
-						Description: "The GitHub username of the officer.",
+						Description: "The GitHub username or profile URL of the officer.",
 					},
 					&discord.StringOption{
 						OptionName:  "linkedin",
-						Description: "The LinkedIn username of the officer.",
+						Description: "The LinkedIn profile name (after linkedin.com/in/) or profile URL of the officer.",
 					},
 					&discord.StringOption{
 						OptionName:  "instagram",
-						Description: "The Instagram username of the officer.",
+						Description: "The Instagram username or profile URL of the officer.",
 					},
 					&discord.StringOption{
 						OptionName:  "website",
-						Description: "The website of the officer.",
+						Description: "The website URL of the officer, e.g. example.com.",
+					},
+				},
+			},
+			&discord.SubcommandOption{
+				OptionName:  "unset",
+				Description: "Clear fields of an officer.",
+				Options: []discord.CommandOptionValue{
+					&discord.UserOption{
+						OptionName: "for_user",
+						Description: "The Discord user to clear the fields of. " +
+							"If not specified, then the current user is used.",
+					},
+					&discord.BooleanOption{
+						OptionName:  "github",
+						Description: "Clear the GitHub username.",
+					},
+					&discord.BooleanOption{
+						OptionName:  "linkedin",
+						Description: "Clear the LinkedIn profile.",
+					},
+					&discord.BooleanOption{
+						OptionName:  "instagram",
+						Description: "Clear the Instagram username.",
+					},
+					&discord.BooleanOption{
+						OptionName:  "website",
+						Description: "Clear the website.",
+					},
+					&discord.StringOption{
+						OptionName:   "field",
+						Description:  "Clear a field set with /officer set-field, such as bio or socials.twitter.",
+						Autocomplete: true,
+					},
+				},
+			},
+			&discord.SubcommandOption{
+				OptionName: "set-field",
+				Description: "Set a field that the bot doesn't know about yet, " +
+					"such as bio or socials.twitter.",
+				Options: []discord.CommandOptionValue{
+					&discord.StringOption{
+						OptionName: "field",
+						Description: "The field name. Prefix with socials. " +
+							"for a social media platform.",
+						Required:     true,
+						Autocomplete: true,
+					},
+					&discord.StringOption{
+						OptionName:  "value",
+						Description: "The value of the field.",
+						Required:    true,
+					},
+					&discord.UserOption{
+						OptionName: "for_user",
+						Description: "The Discord user to set the field for. " +
+							"If not specified, then the current user is used.",
 					},
 				},
 			},
@@ -61,29 +129,87 @@ var commands = []api.CreateCommandData{
 				OptionName:  "add-term",
 				Description: "Add a new term of an officer.",
 				Options: []discord.CommandOptionValue{
+					&discord.StringOption{
+						OptionName:   "title",
+						Description:  "The position title held during the term, as listed in tiers.json.",
+						Required:     true,
+						Autocomplete: true,
+					},
 					&discord.UserOption{
 						OptionName: "for_user",
+						Description: "The Discord user to add the term for. " +
+							"If not specified, then the current user is used.",
 					},
 					&discord.StringOption{
-						OptionName: "semester",
-						Description: "The semester of the term. If not specified, then the " +
-							"current semester is used.",
-						Choices: []discord.StringChoice{
-							{Value: "F", Name: "Fall"},
-							{Value: "S", Name: "Spring"},
-						},
-					},
-					&discord.NumberOption{
-						OptionName: "year",
-						Description: "The year of the term. If not specified, then the " +
-							"current year is used.",
+						OptionName: "term",
+						Description: "The term, e.g. F22 or Fall 2022. If not specified, then the " +
+							"current term is used.",
+						Autocomplete: true,
+					},
+				},
+			},
+			&discord.SubcommandOption{
+				OptionName:  "picture",
+				Description: "Upload a picture of an officer. It's cropped to a square.",
+				Options: []discord.CommandOptionValue{
+					&discord.AttachmentOption{
+						OptionName:  "picture",
+						Description: "A JPEG, PNG or GIF picture, from 256x256 to 2048x2048.",
+						Required:    true,
+					},
+					&discord.UserOption{
+						OptionName: "for_user",
+						Description: "The Discord user to upload the picture for. " +
+							"If not specified, then the current user is used.",
 					},
 				},
 			},
 			&discord.SubcommandOption{
 				OptionName: "pr",
-				Description: "Create a new PR or update the existing one containing " +
-					"all the changes made previously. One user can have one ongoing PR.",
+				Description: "Create or update a PR with all of your changes. " +
+					"One user can have one ongoing PR.",
+			},
+			&discord.SubcommandOption{
+				OptionName:  "show",
+				Description: "Show the information of an officer.",
+				Options: []discord.CommandOptionValue{
+					&discord.UserOption{
+						OptionName: "user",
+						Description: "The Discord user linked to the officer. " +
+							"If not specified, then the current user is used.",
+					},
+					&discord.StringOption{
+						OptionName:   "full_name",
+						Description:  "The full name of the officer.",
+						Autocomplete: true,
+					},
+					&discord.BooleanOption{
+						OptionName: "workspace",
+						Description: "Read from your workspace, which has your unmerged changes, " +
+							"instead of upstream.",
+					},
+				},
+			},
+			&discord.SubcommandOption{
+				OptionName:  "list",
+				Description: "List the officers of a term.",
+				Options: []discord.CommandOptionValue{
+					&discord.StringOption{
+						OptionName: "term",
+						Description: "The term, e.g. F22 or Fall 2022. If not specified, then the " +
+							"current term is used.",
+						Autocomplete: true,
+					},
+					&discord.BooleanOption{
+						OptionName: "workspace",
+						Description: "Read from your workspace, which has your unmerged changes, " +
+							"instead of upstream.",
+					},
+				},
+			},
+			&discord.SubcommandOption{
+				OptionName:  "migrate-ids",
+				Description: "Record the Discord IDs of officers linked by their tag only. Editors only.",
 			},
 		},
 	},
@@ -0,0 +1,180 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/diamondburned/officer-data/internal/gitwork"
+	"github.com/pkg/errors"
+)
+
+// componentHandlerFunc handles a component interaction. arg is the part of
+// the component's custom ID after the handler's name.
+type componentHandlerFunc func(ctx context.Context, ev *discord.InteractionEvent, arg string) *api.InteractionResponse
+
+// componentID creates a custom ID for a component that is routed to the
+// handler with the given name.
+func componentID(name, arg string) discord.ComponentID {
+	return discord.ComponentID(name + ":" + arg)
+}
+
+func (h *Handler) addComponent(name string, f componentHandlerFunc) {
+	if _, ok := h.components[name]; ok {
+		panic("component " + name + " already exists")
+	}
+	h.components[name] = f
+}
+
+func (h *Handler) handleComponent(ev *discord.InteractionEvent, data discord.ComponentInteraction) *api.InteractionResponse {
+	name, arg, _ := strings.Cut(string(data.ID()), ":")
+
+	f, ok := h.components[name]
+	if !ok {
+		return nil
+	}
+
+	return f(h.state.Context(), ev, arg)
+}
+
+// updateMessage creates a response that replaces the message that the
+// component is on with the given content, removing all components.
+func updateMessage(content string) *api.InteractionResponse {
+	return &api.InteractionResponse{
+		Type: api.UpdateMessage,
+		Data: &api.InteractionResponseData{
+			Content:         option.NewNullableString(content),
+			Components:      &discord.ContainerComponents{},
+			AllowedMentions: &api.AllowedMentions{ /* none */ },
+		},
+	}
+}
+
+// pendingEditTimeout is how long a previewed edit can be confirmed for. It
+// matches the lifetime of an interaction token.
+const pendingEditTimeout = 15 * time.Minute
+
+// confirmTimeout is how long the confirm button waits for the commit before
+// deferring its response, so that Discord's 3-second deadline is never missed.
+const confirmTimeout = 2 * time.Second
+
+type pendingEdit struct {
+	user userKey
+	// repo is the workspace that the edit is committed into. It's acquired
+	// until the edit is confirmed, cancelled or expires.
+	repo    *gitwork.PooledRepository
+	commit  pendingCommit
+	expires time.Time
+}
+
+// addPendingEdit remembers the edit so it can be confirmed later. It returns
+// the ID of the edit. The workspace is kept from being evicted until then.
+func (h *Handler) addPendingEdit(ev *discord.InteractionEvent, repo *gitwork.PooledRepository, commit pendingCommit) string {
+	var idBytes [8]byte
+	if _, err := rand.Read(idBytes[:]); err != nil {
+		panic(err)
+	}
+	id := hex.EncodeToString(idBytes[:])
+
+	now := time.Now()
+
+	h.pendingMu.Lock()
+	defer h.pendingMu.Unlock()
+
+	for id, edit := range h.pending {
+		if now.After(edit.expires) {
+			delete(h.pending, id)
+			edit.repo.Release()
+		}
+	}
+
+	repo.Acquire()
+	h.pending[id] = &pendingEdit{
+		user:    userKey{ev.GuildID, ev.SenderID()},
+		repo:    repo,
+		commit:  commit,
+		expires: now.Add(pendingEditTimeout),
+	}
+
+	return id
+}
+
+// takePendingEdit removes and returns the pending edit with the given ID if
+// it belongs to the user. The caller must release the edit's workspace.
+func (h *Handler) takePendingEdit(ev *discord.InteractionEvent, id string) (*pendingEdit, error) {
+	h.pendingMu.Lock()
+	defer h.pendingMu.Unlock()
+
+	edit, ok := h.pending[id]
+	if !ok {
+		return nil, errors.New("this edit has expired, please run the command again")
+	}
+
+	if time.Now().After(edit.expires) {
+		delete(h.pending, id)
+		edit.repo.Release()
+		return nil, errors.New("this edit has expired, please run the command again")
+	}
+
+	if edit.user != (userKey{ev.GuildID, ev.SenderID()}) {
+		return nil, errors.New("this edit belongs to someone else")
+	}
+
+	delete(h.pending, id)
+	return edit, nil
+}
+
+func (h *Handler) handleEditConfirm(ctx context.Context, ev *discord.InteractionEvent, id string) *api.InteractionResponse {
+	edit, err := h.takePendingEdit(ev, id)
+	if err != nil {
+		return updateMessage("**Error:** " + err.Error())
+	}
+
+	// Committing may wait for a Sync of the same workspace, so reply as soon
+	// as possible and edit the message once the commit is done if it takes
+	// too long.
+	contentCh := make(chan string, 1)
+	go func() {
+		defer edit.repo.Release()
+
+		content, err := h.commitOfficers(ctx, ev, edit.commit)
+		if err != nil {
+			content = "**Error:** " + err.Error()
+		}
+		contentCh <- content
+	}()
+
+	select {
+	case content := <-contentCh:
+		return updateMessage(content)
+	case <-time.After(confirmTimeout):
+		go func() {
+			content := <-contentCh
+			_, err := h.state.EditInteractionResponse(ev.AppID, ev.Token, api.EditInteractionResponseData{
+				Content:         option.NewNullableString(content),
+				Components:      &discord.ContainerComponents{},
+				AllowedMentions: &api.AllowedMentions{ /* none */ },
+			})
+			if err != nil {
+				log.Println("cannot edit confirmed edit message:", err)
+			}
+		}()
+		return &api.InteractionResponse{Type: api.DeferredMessageUpdate}
+	}
+}
+
+func (h *Handler) handleEditCancel(ctx context.Context, ev *discord.InteractionEvent, id string) *api.InteractionResponse {
+	edit, err := h.takePendingEdit(ev, id)
+	if err != nil {
+		return updateMessage("**Error:** " + err.Error())
+	}
+	edit.repo.Release()
+
+	return updateMessage("Cancelled, nothing was changed.")
+}
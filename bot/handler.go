@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"sync"
 	"time"
 
 	"github.com/diamondburned/arikawa/v3/api"
@@ -9,20 +10,31 @@ import (
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/state"
 	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/diamondburned/officer-data/acmcsuf"
+	"github.com/diamondburned/officer-data/internal/forge"
 	"github.com/diamondburned/officer-data/internal/gitwork"
 	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
 )
 
 // Intents is the set of intents that the bot needs.
 var Intents = 0 |
 	gateway.IntentGuilds
 
-// New creates a new bot instance.
-func New(state *state.State, gitPool *gitwork.Pool) *Handler {
+// New creates a new bot instance. forge may be nil, in which case pull
+// requests cannot be made.
+func New(state *state.State, gitPool *gitwork.Pool, forge forge.Forge) *Handler {
 	h := Handler{
+		Editors:  DefaultEditorPolicy,
+		Calendar: acmcsuf.DefaultCalendar(),
+
 		state:  state,
 		router: cmdroute.NewRouter(),
 		gits:   gitPool,
+		forge:  forge,
+
+		components: make(map[string]componentHandlerFunc),
+		pending:    make(map[string]*pendingEdit),
 	}
 
 	h.router.Use(cmdroute.UseContext(state.Context()))
@@ -32,25 +44,73 @@ func New(state *state.State, gitPool *gitwork.Pool) *Handler {
 	h.router.Sub("officer", func(r *cmdroute.Router) {
 		r.AddFunc("link", h.handleLink)
 		r.AddFunc("set", h.handleSet)
+		r.AddFunc("set-field", h.handleSetField)
+		r.AddFunc("unset", h.handleUnset)
 		r.AddFunc("add-term", h.handleAddTerm)
+		r.AddFunc("picture", h.handlePicture)
 		r.AddFunc("pr", h.handlePR)
+		r.AddFunc("show", h.handleShow)
+		r.AddFunc("list", h.handleList)
+		r.AddFunc("migrate-ids", h.handleMigrateIDs)
+
+		r.AddAutocompleterFunc("link", h.autocompleteOfficer)
+		r.AddAutocompleterFunc("show", h.autocompleteOfficer)
+		r.AddAutocompleterFunc("set-field", h.autocompleteField)
+		r.AddAutocompleterFunc("unset", h.autocompleteField)
+		r.AddAutocompleterFunc("add-term", h.autocompleteTerm)
+		r.AddAutocompleterFunc("list", h.autocompleteTerm)
 	})
 
+	h.addComponent("edit-confirm", h.handleEditConfirm)
+	h.addComponent("edit-cancel", h.handleEditCancel)
+	h.addComponent("officer-list", h.handleListPage)
+
 	return &h
 }
 
 type Handler struct {
+	// Editors is the policy that decides who may act on other users' officer
+	// records.
+	Editors EditorPolicy
+	// Calendar decides the current term, which commands default to.
+	Calendar acmcsuf.Calendar
+
 	state  *state.State
 	router *cmdroute.Router
 	gits   *gitwork.Pool
+	forge  forge.Forge
+
+	components map[string]componentHandlerFunc
+
+	pendingMu sync.Mutex
+	pending   map[string]*pendingEdit
+
+	upstreamMu     sync.Mutex
+	upstreamData   *upstreamData
+	upstreamFlight singleflight.Group
+}
+
+// userKey identifies a user within a guild.
+type userKey struct {
+	guildID discord.GuildID
+	userID  discord.UserID
 }
 
 func (h *Handler) HandleInteraction(ev *discord.InteractionEvent) *api.InteractionResponse {
-	resp := h.router.HandleInteraction(ev)
+	var resp *api.InteractionResponse
+	switch data := ev.Data.(type) {
+	case discord.ComponentInteraction:
+		resp = h.handleComponent(ev, data)
+	default:
+		resp = h.router.HandleInteraction(ev)
+	}
 	if resp != nil {
 		return resp
 	}
-	return errorResponse(errors.New("unknown interaction"))
+	return &api.InteractionResponse{
+		Type: api.MessageInteractionWithSource,
+		Data: errorResponse(errors.New("unknown interaction")),
+	}
 }
 
 // OverwriteCommands overwrites the commands to the ones defined in Commands.
@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+)
+
+// EditorPolicy decides who may act on other users' officer records. Users can
+// always act on their own records.
+type EditorPolicy struct {
+	// Roles maps each guild to the roles whose members are editors.
+	Roles map[discord.GuildID][]discord.RoleID
+	// Permissions is the set of permissions that also make a member an
+	// editor. Having any one of them is enough. If zero, then only Roles is
+	// used.
+	Permissions discord.Permissions
+}
+
+// DefaultEditorPolicy is the default editor policy, which lets anyone who can
+// manage roles edit other users' records.
+var DefaultEditorPolicy = EditorPolicy{
+	Permissions: discord.PermissionManageRoles,
+}
+
+// ParseEditorRoles parses a list of editor roles in the format
+// "guildID:roleID,guildID:roleID,...".
+func ParseEditorRoles(s string) (map[discord.GuildID][]discord.RoleID, error) {
+	roles := make(map[discord.GuildID][]discord.RoleID)
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		guild, role, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid editor role %q, expected guildID:roleID", pair)
+		}
+
+		guildID, err := discord.ParseSnowflake(guild)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid guild ID in %q", pair)
+		}
+
+		roleID, err := discord.ParseSnowflake(role)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid role ID in %q", pair)
+		}
+
+		roles[discord.GuildID(guildID)] = append(roles[discord.GuildID(guildID)], discord.RoleID(roleID))
+	}
+
+	return roles, nil
+}
+
+// errNotEditor is returned when a user tries to act on another user's record
+// without being an editor.
+type errNotEditor struct {
+	policy  EditorPolicy
+	guildID discord.GuildID
+}
+
+func (err errNotEditor) Error() string {
+	var requirements []string
+	for _, roleID := range err.policy.Roles[err.guildID] {
+		requirements = append(requirements, roleID.Mention())
+	}
+	if err.policy.Permissions.Has(discord.PermissionManageRoles) {
+		requirements = append(requirements, "the Manage Roles permission")
+	} else if err.policy.Permissions != 0 {
+		requirements = append(requirements, "an admin permission")
+	}
+
+	if len(requirements) == 0 {
+		return "only the user themselves can edit their officer record"
+	}
+
+	return "you need " + strings.Join(requirements, " or ") + " to edit other users' officer records"
+}
+
+// authorizeEditor returns an error if the sender of the interaction is not
+// allowed to act on other users' records.
+func (h *Handler) authorizeEditor(ev *discord.InteractionEvent) error {
+	if ev.Member == nil || !ev.GuildID.IsValid() {
+		return errNotEditor{h.Editors, ev.GuildID}
+	}
+
+	for _, editorRole := range h.Editors.Roles[ev.GuildID] {
+		for _, roleID := range ev.Member.RoleIDs {
+			if roleID == editorRole {
+				return nil
+			}
+		}
+	}
+
+	if h.Editors.Permissions != 0 {
+		perms, err := h.memberPermissions(ev.GuildID, ev.Member)
+		if err != nil {
+			return errors.Wrap(err, "failed to get permissions")
+		}
+
+		if perms.Has(discord.PermissionAdministrator) || perms&h.Editors.Permissions != 0 {
+			return nil
+		}
+	}
+
+	return errNotEditor{h.Editors, ev.GuildID}
+}
+
+// memberPermissions returns the guild-wide permissions of the member from the
+// roles that the interaction carries. Unlike State.Permissions, it doesn't
+// need the channel or the member to be cached, which the bot's intents don't
+// provide. arikawa's Member doesn't expose the interaction's permissions
+// field, so they are computed from the guild's roles instead.
+func (h *Handler) memberPermissions(guildID discord.GuildID, member *discord.Member) (discord.Permissions, error) {
+	guild, err := h.state.Guild(guildID)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get guild")
+	}
+
+	if guild.OwnerID == member.User.ID {
+		return discord.PermissionAll, nil
+	}
+
+	roles, err := h.state.Roles(guildID)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get roles")
+	}
+
+	var perms discord.Permissions
+	for _, role := range roles {
+		// The @everyone role has the same ID as the guild.
+		if discord.GuildID(role.ID) == guildID {
+			perms |= role.Permissions
+			continue
+		}
+		for _, roleID := range member.RoleIDs {
+			if roleID == role.ID {
+				perms |= role.Permissions
+				break
+			}
+		}
+	}
+
+	return perms, nil
+}
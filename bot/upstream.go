@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/diamondburned/officer-data/acmcsuf"
+	"github.com/pkg/errors"
+)
+
+// upstreamWorkspace is the pool workspace used for reading the upstream
+// branch. Guild workspaces are named after snowflakes, so this never clashes.
+const upstreamWorkspace = "upstream"
+
+// upstreamTTL is how long the parsed upstream data is considered fresh for.
+const upstreamTTL = 5 * time.Minute
+
+// upstreamData is the parsed data files of the upstream default branch.
+type upstreamData struct {
+	Officers acmcsuf.Officers
+	Tiers    acmcsuf.Tiers
+	Fetched  time.Time
+}
+
+func (d *upstreamData) isStale() bool {
+	return d == nil || time.Since(d.Fetched) > upstreamTTL
+}
+
+// upstream returns the parsed data files of the upstream default branch. The
+// data is cached, and it is only fetched again once it's stale.
+func (h *Handler) upstream(ctx context.Context) (*upstreamData, error) {
+	h.upstreamMu.Lock()
+	data := h.upstreamData
+	h.upstreamMu.Unlock()
+
+	if !data.isStale() {
+		return data, nil
+	}
+
+	v, err, _ := h.upstreamFlight.Do("", func() (interface{}, error) {
+		return h.fetchUpstream(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(*upstreamData), nil
+}
+
+// cachedUpstream returns the cached upstream data without blocking. If the
+// data is stale, then it is refreshed in the background. It returns nil if
+// nothing has been fetched yet.
+func (h *Handler) cachedUpstream() *upstreamData {
+	h.upstreamMu.Lock()
+	data := h.upstreamData
+	h.upstreamMu.Unlock()
+
+	if data.isStale() {
+		go func() {
+			if _, err := h.upstream(h.state.Context()); err != nil {
+				log.Println("cannot refresh upstream data:", err)
+			}
+		}()
+	}
+
+	return data
+}
+
+func (h *Handler) fetchUpstream(ctx context.Context) (*upstreamData, error) {
+	repo, err := h.gits.Clone(ctx, false, upstreamWorkspace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to clone git repo")
+	}
+
+	upstream, err := repo.Fetch(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch upstream")
+	}
+
+	data := upstreamData{Fetched: time.Now()}
+
+	officersJSON, err := repo.ReadFileAt(upstream, acmcsuf.OfficersJSONPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read officers.json")
+	}
+
+	if err := json.Unmarshal(officersJSON, &data.Officers); err != nil {
+		return nil, errors.Wrap(err, "failed to decode officers.json")
+	}
+
+	tiersJSON, err := repo.ReadFileAt(upstream, acmcsuf.TiersJSONPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read tiers.json")
+	}
+
+	data.Tiers, err = acmcsuf.LoadTiers(bytes.NewReader(tiersJSON))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load tiers.json")
+	}
+
+	h.upstreamMu.Lock()
+	h.upstreamData = &data
+	h.upstreamMu.Unlock()
+
+	return &data, nil
+}
@@ -0,0 +1,50 @@
+// Package forge abstracts over code forges (such as GitHub) that host the
+// upstream repository and accept pull requests.
+package forge
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when the requested pull request does not exist.
+var ErrNotFound = errors.New("pull request not found")
+
+// PullRequest describes a pull request on a forge.
+type PullRequest struct {
+	Number int
+	URL    string
+	Title  string
+	Head   string
+	Base   string
+	Open   bool
+}
+
+// PullRequestOptions describes the contents of a pull request to be created or
+// updated.
+type PullRequestOptions struct {
+	Title string
+	Body  string
+	// Head is the branch containing the changes.
+	Head string
+	// Base is the branch that the changes are to be merged into.
+	Base string
+}
+
+// Forge is a code forge that can create and update pull requests.
+type Forge interface {
+	// DefaultBranch returns the default branch of the upstream repository,
+	// which is where pull requests are merged into.
+	DefaultBranch(ctx context.Context) (string, error)
+	// PullRequest returns the pull request with the given number. If it does
+	// not exist, then ErrNotFound is returned.
+	PullRequest(ctx context.Context, number int) (*PullRequest, error)
+	// FindPullRequest returns the open pull request whose head is the given
+	// branch. If there is none, then ErrNotFound is returned.
+	FindPullRequest(ctx context.Context, head string) (*PullRequest, error)
+	// CreatePullRequest creates a new pull request.
+	CreatePullRequest(ctx context.Context, opts PullRequestOptions) (*PullRequest, error)
+	// UpdatePullRequest updates the title and body of an existing pull
+	// request. The head and base branches are left untouched.
+	UpdatePullRequest(ctx context.Context, number int, opts PullRequestOptions) (*PullRequest, error)
+}
@@ -0,0 +1,268 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultGitHubURL is the default base URL of the GitHub REST API.
+const DefaultGitHubURL = "https://api.github.com"
+
+// GitHub is a Forge backed by the GitHub REST API.
+type GitHub struct {
+	// BaseURL is the base URL of the API. It can be pointed to a local server
+	// for testing.
+	BaseURL string
+	// Client is the HTTP client used for requests.
+	Client *http.Client
+	// Owner and Repo identify the upstream repository.
+	Owner string
+	Repo  string
+	// Token returns the access token used for authenticating requests. If
+	// it's nil, then requests are not authenticated.
+	Token TokenSource
+}
+
+var _ Forge = (*GitHub)(nil)
+
+// TokenSource returns an access token. It's called before every request, so
+// it may return a new token each time, e.g. to refresh an expiring one.
+type TokenSource func(ctx context.Context) (string, error)
+
+// StaticToken returns a TokenSource that always returns the given token, such
+// as a personal access token.
+func StaticToken(token string) TokenSource {
+	return func(context.Context) (string, error) { return token, nil }
+}
+
+// NewGitHub creates a new GitHub forge for the given repository.
+func NewGitHub(owner, repo string, token TokenSource) *GitHub {
+	return &GitHub{
+		BaseURL: DefaultGitHubURL,
+		Client:  http.DefaultClient,
+		Owner:   owner,
+		Repo:    repo,
+		Token:   token,
+	}
+}
+
+// ParseGitHubRemote parses the owner and repository name out of a GitHub
+// remote URL, such as https://github.com/owner/repo.git or
+// git@github.com:owner/repo.git.
+func ParseGitHubRemote(remoteURL string) (owner, repo string, err error) {
+	path := remoteURL
+	if strings.HasPrefix(path, "git@") {
+		_, path, _ = strings.Cut(path, ":")
+	} else {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", "", errors.Wrap(err, "invalid remote URL")
+		}
+		path = u.Path
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+
+	owner, repo, ok := strings.Cut(path, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("remote URL %q is not a GitHub repository", remoteURL)
+	}
+
+	return owner, repo, nil
+}
+
+type githubPullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (pr githubPullRequest) convert() *PullRequest {
+	return &PullRequest{
+		Number: pr.Number,
+		URL:    pr.HTMLURL,
+		Title:  pr.Title,
+		Head:   pr.Head.Ref,
+		Base:   pr.Base.Ref,
+		Open:   pr.State == "open",
+	}
+}
+
+// DefaultBranch implements Forge.
+func (g *GitHub) DefaultBranch(ctx context.Context) (string, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := g.do(ctx, "GET", g.repoPath(), nil, &repo); err != nil {
+		return "", err
+	}
+	return repo.DefaultBranch, nil
+}
+
+// PullRequest implements Forge.
+func (g *GitHub) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var pr githubPullRequest
+	if err := g.do(ctx, "GET", g.repoPath("pulls", fmt.Sprint(number)), nil, &pr); err != nil {
+		if isStatus(err, http.StatusNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return pr.convert(), nil
+}
+
+// FindPullRequest implements Forge.
+func (g *GitHub) FindPullRequest(ctx context.Context, head string) (*PullRequest, error) {
+	q := url.Values{
+		"state": {"open"},
+		"head":  {g.Owner + ":" + head},
+	}
+
+	var prs []githubPullRequest
+	if err := g.do(ctx, "GET", g.repoPath("pulls")+"?"+q.Encode(), nil, &prs); err != nil {
+		return nil, err
+	}
+
+	if len(prs) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return prs[0].convert(), nil
+}
+
+// CreatePullRequest implements Forge.
+func (g *GitHub) CreatePullRequest(ctx context.Context, opts PullRequestOptions) (*PullRequest, error) {
+	body := map[string]string{
+		"title": opts.Title,
+		"body":  opts.Body,
+		"head":  opts.Head,
+		"base":  opts.Base,
+	}
+
+	var pr githubPullRequest
+	if err := g.do(ctx, "POST", g.repoPath("pulls"), body, &pr); err != nil {
+		return nil, err
+	}
+	return pr.convert(), nil
+}
+
+// UpdatePullRequest implements Forge.
+func (g *GitHub) UpdatePullRequest(ctx context.Context, number int, opts PullRequestOptions) (*PullRequest, error) {
+	body := map[string]string{
+		"title": opts.Title,
+		"body":  opts.Body,
+	}
+
+	var pr githubPullRequest
+	if err := g.do(ctx, "PATCH", g.repoPath("pulls", fmt.Sprint(number)), body, &pr); err != nil {
+		return nil, err
+	}
+	return pr.convert(), nil
+}
+
+func (g *GitHub) repoPath(parts ...string) string {
+	path := "/repos/" + url.PathEscape(g.Owner) + "/" + url.PathEscape(g.Repo)
+	for _, part := range parts {
+		path += "/" + url.PathEscape(part)
+	}
+	return path
+}
+
+func (g *GitHub) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return errors.Wrap(err, "cannot encode request")
+		}
+		body = bytes.NewReader(b)
+	}
+
+	baseURL := g.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultGitHubURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(baseURL, "/")+path, body)
+	if err != nil {
+		return errors.Wrap(err, "cannot create request")
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if g.Token != nil {
+		token, err := g.Token(ctx)
+		if err != nil {
+			return errors.Wrap(err, "cannot get GitHub token")
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "cannot %s %s", method, path)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var ghErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&ghErr)
+		return &StatusError{
+			Code:    resp.StatusCode,
+			Status:  resp.Status,
+			Message: ghErr.Message,
+		}
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return errors.Wrap(err, "cannot decode response")
+		}
+	}
+
+	return nil
+}
+
+// StatusError is returned when GitHub responds with an unsuccessful status.
+type StatusError struct {
+	// Code is the HTTP status code, e.g. 404.
+	Code int
+	// Status is the HTTP status line, e.g. "404 Not Found".
+	Status string
+	// Message is the message that GitHub gave, if any.
+	Message string
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("GitHub returned %s: %s", e.Status, e.Message)
+}
+
+func isStatus(err error, code int) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Code == code
+}
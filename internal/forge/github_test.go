@@ -0,0 +1,235 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestGitHub(t *testing.T, handler http.HandlerFunc) *GitHub {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	g := NewGitHub("acmcsufoss", "acmcsuf.com", StaticToken("token"))
+	g.BaseURL = srv.URL
+	g.Client = srv.Client()
+	return g
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestGitHubPullRequest(t *testing.T) {
+	g := newTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q", got)
+		}
+
+		switch r.URL.Path {
+		case "/repos/acmcsufoss/acmcsuf.com/pulls/1":
+			writeJSON(w, 200, map[string]interface{}{
+				"number":   1,
+				"html_url": "https://github.com/acmcsufoss/acmcsuf.com/pull/1",
+				"title":    "Update officers",
+				"state":    "open",
+				"head":     map[string]string{"ref": "officer-data/1/2"},
+				"base":     map[string]string{"ref": "main"},
+			})
+		default:
+			writeJSON(w, 404, map[string]string{"message": "Not Found"})
+		}
+	})
+
+	ctx := context.Background()
+
+	pr, err := g.PullRequest(ctx, 1)
+	if err != nil {
+		t.Fatal("PullRequest(1):", err)
+	}
+	want := PullRequest{
+		Number: 1,
+		URL:    "https://github.com/acmcsufoss/acmcsuf.com/pull/1",
+		Title:  "Update officers",
+		Head:   "officer-data/1/2",
+		Base:   "main",
+		Open:   true,
+	}
+	if *pr != want {
+		t.Errorf("PullRequest(1) = %+v, want %+v", *pr, want)
+	}
+
+	if _, err := g.PullRequest(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("PullRequest(2) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGitHubFindPullRequest(t *testing.T) {
+	var prs []map[string]interface{}
+
+	g := newTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/acmcsufoss/acmcsuf.com/pulls" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("head"); got != "acmcsufoss:officer-data/1/2" {
+			t.Errorf("head = %q", got)
+		}
+		if got := r.URL.Query().Get("state"); got != "open" {
+			t.Errorf("state = %q", got)
+		}
+		writeJSON(w, 200, prs)
+	})
+
+	ctx := context.Background()
+
+	if _, err := g.FindPullRequest(ctx, "officer-data/1/2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindPullRequest with no PRs: error = %v, want ErrNotFound", err)
+	}
+
+	prs = append(prs, map[string]interface{}{"number": 3, "state": "open"})
+
+	pr, err := g.FindPullRequest(ctx, "officer-data/1/2")
+	if err != nil {
+		t.Fatal("FindPullRequest:", err)
+	}
+	if pr.Number != 3 || !pr.Open {
+		t.Errorf("FindPullRequest = %+v, want open #3", *pr)
+	}
+}
+
+func TestGitHubCreatePullRequest(t *testing.T) {
+	g := newTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repos/acmcsufoss/acmcsuf.com/pulls" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error("cannot decode body:", err)
+		}
+
+		writeJSON(w, 201, map[string]interface{}{
+			"number": 4,
+			"title":  body["title"],
+			"state":  "open",
+			"head":   map[string]string{"ref": body["head"]},
+			"base":   map[string]string{"ref": body["base"]},
+		})
+	})
+
+	pr, err := g.CreatePullRequest(context.Background(), PullRequestOptions{
+		Title: "Update officers",
+		Body:  "- add term",
+		Head:  "officer-data/1/2",
+		Base:  "main",
+	})
+	if err != nil {
+		t.Fatal("CreatePullRequest:", err)
+	}
+	if pr.Number != 4 || pr.Title != "Update officers" || pr.Head != "officer-data/1/2" || pr.Base != "main" {
+		t.Errorf("CreatePullRequest = %+v", *pr)
+	}
+}
+
+func TestGitHubErrors(t *testing.T) {
+	g := newTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acmcsufoss/acmcsuf.com":
+			writeJSON(w, 404, map[string]string{"message": "Not Found"})
+		default:
+			writeJSON(w, 403, map[string]string{"message": "Resource not accessible"})
+		}
+	})
+
+	ctx := context.Background()
+
+	// A missing repository is not a missing pull request.
+	_, err := g.DefaultBranch(ctx)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("DefaultBranch error = %v, want a non-ErrNotFound error", err)
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != 404 {
+		t.Errorf("DefaultBranch error = %v, want a 404 StatusError", err)
+	}
+
+	_, err = g.PullRequest(ctx, 1)
+	if !errors.As(err, &statusErr) || statusErr.Code != 403 || statusErr.Message != "Resource not accessible" {
+		t.Errorf("PullRequest error = %v, want a 403 StatusError", err)
+	}
+}
+
+func TestGitHubTokenSource(t *testing.T) {
+	var auth string
+	g := newTestGitHub(t, func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		writeJSON(w, 200, map[string]string{"default_branch": "main"})
+	})
+
+	// The token is asked for on every request, so that it can be refreshed.
+	var calls int
+	g.Token = func(ctx context.Context) (string, error) {
+		calls++
+		if calls > 2 {
+			return "", errors.New("no more tokens")
+		}
+		return fmt.Sprintf("token%d", calls), nil
+	}
+
+	ctx := context.Background()
+
+	for _, want := range []string{"Bearer token1", "Bearer token2"} {
+		if _, err := g.DefaultBranch(ctx); err != nil {
+			t.Fatal("DefaultBranch:", err)
+		}
+		if auth != want {
+			t.Errorf("Authorization = %q, want %q", auth, want)
+		}
+	}
+
+	auth = ""
+	if _, err := g.DefaultBranch(ctx); err == nil {
+		t.Error("DefaultBranch succeeded without a token")
+	}
+	if auth != "" {
+		t.Error("DefaultBranch sent a request without a token")
+	}
+}
+
+func TestParseGitHubRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		owner  string
+		repo   string
+		err    bool
+	}{
+		{"https://github.com/acmcsufoss/acmcsuf.com.git", "acmcsufoss", "acmcsuf.com", false},
+		{"https://github.com/acmcsufoss/acmcsuf.com", "acmcsufoss", "acmcsuf.com", false},
+		{"git@github.com:acmcsufoss/acmcsuf.com.git", "acmcsufoss", "acmcsuf.com", false},
+		{"https://github.com/acmcsufoss", "", "", true},
+		{"https://github.com/a/b/c", "", "", true},
+	}
+
+	for _, test := range tests {
+		owner, repo, err := ParseGitHubRemote(test.remote)
+		if test.err {
+			if err == nil {
+				t.Errorf("ParseGitHubRemote(%q) = %q, %q, want error", test.remote, owner, repo)
+			}
+			continue
+		}
+		if err != nil || owner != test.owner || repo != test.repo {
+			t.Errorf("ParseGitHubRemote(%q) = %q, %q, %v, want %q, %q",
+				test.remote, owner, repo, err, test.owner, test.repo)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package gitwork
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	gittransport "github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// Auth provides the credentials used to clone, fetch from and push to a
+// remote. AuthMethod is called before every such operation, so it may return
+// new credentials each time, e.g. to refresh an expiring token.
+//
+// Credentials must never be logged or put into errors.
+type Auth interface {
+	AuthMethod(ctx context.Context) (gittransport.AuthMethod, error)
+}
+
+// authMethod returns the credentials from the given Auth. A nil Auth means no
+// credentials.
+func authMethod(ctx context.Context, auth Auth) (gittransport.AuthMethod, error) {
+	if auth == nil {
+		return nil, nil
+	}
+
+	method, err := auth.AuthMethod(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get git credentials")
+	}
+
+	return method, nil
+}
+
+// DefaultTokenUsername is the username used by TokenAuth if none is given.
+// GitHub ignores the username when a token is used as the password.
+const DefaultTokenUsername = "x-access-token"
+
+// TokenAuth authenticates to HTTPS remotes using an access token, such as a
+// GitHub personal access token.
+type TokenAuth struct {
+	// Username is the username sent along with the token. If it's empty,
+	// then DefaultTokenUsername is used.
+	Username string
+	Token    string
+}
+
+var _ Auth = TokenAuth{}
+
+// AuthMethod implements Auth.
+func (a TokenAuth) AuthMethod(ctx context.Context) (gittransport.AuthMethod, error) {
+	username := a.Username
+	if username == "" {
+		username = DefaultTokenUsername
+	}
+
+	return &githttp.BasicAuth{
+		Username: username,
+		Password: a.Token,
+	}, nil
+}
+
+// SSHKeyAuth authenticates to SSH remotes using a private key file.
+type SSHKeyAuth struct {
+	// User is the SSH user, which is usually "git".
+	User string
+	// Path is the path to the private key file.
+	Path string
+	// Passphrase decrypts the private key if it's encrypted.
+	Passphrase string
+
+	mu     sync.Mutex
+	method gittransport.AuthMethod
+}
+
+var _ Auth = (*SSHKeyAuth)(nil)
+
+// NewSSHKeyAuth creates a new SSHKeyAuth. The key file is only read once it's
+// first used, and it's read again on the next use if that fails, so that a
+// key that is fixed later is picked up without a restart.
+func NewSSHKeyAuth(user, path, passphrase string) *SSHKeyAuth {
+	return &SSHKeyAuth{
+		User:       user,
+		Path:       path,
+		Passphrase: passphrase,
+	}
+}
+
+// AuthMethod implements Auth.
+func (a *SSHKeyAuth) AuthMethod(ctx context.Context) (gittransport.AuthMethod, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.method != nil {
+		return a.method, nil
+	}
+
+	method, err := gitssh.NewPublicKeysFromFile(a.User, a.Path, a.Passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load SSH key")
+	}
+
+	a.method = method
+	return method, nil
+}
+
+// SSHAgentAuth authenticates to SSH remotes using the SSH agent listening on
+// $SSH_AUTH_SOCK. The value is the SSH user, which is usually "git".
+type SSHAgentAuth string
+
+var _ Auth = SSHAgentAuth("")
+
+// AuthMethod implements Auth.
+func (a SSHAgentAuth) AuthMethod(ctx context.Context) (gittransport.AuthMethod, error) {
+	method, err := gitssh.NewSSHAgentAuth(string(a))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot use SSH agent")
+	}
+	return method, nil
+}
@@ -0,0 +1,215 @@
+package gitwork
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	gittransport "github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// DefaultGitHubAPIURL is the default base URL of the GitHub REST API.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// gitHubTokenSlack is how long before its expiry an installation token is
+// replaced, so that it doesn't expire in the middle of a push.
+const gitHubTokenSlack = 5 * time.Minute
+
+// GitHubAppAuth authenticates to GitHub HTTPS remotes as an installation of a
+// GitHub App. Installation tokens last an hour; a new one is requested
+// whenever the current one is about to expire.
+type GitHubAppAuth struct {
+	// AppID is the ID of the GitHub App.
+	AppID int64
+	// InstallationID is the ID of the app's installation on the account
+	// that owns the repository.
+	InstallationID int64
+	// PrivateKey is the app's private key, used to sign the requests for
+	// installation tokens.
+	PrivateKey *rsa.PrivateKey
+	// BaseURL is the base URL of the API. It can be pointed to a local server
+	// for testing.
+	BaseURL string
+	// Client is the HTTP client used for requests.
+	Client *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+var _ Auth = (*GitHubAppAuth)(nil)
+
+// NewGitHubAppAuth creates a new GitHubAppAuth. privateKey is the PEM-encoded
+// private key that GitHub generates for the app.
+func NewGitHubAppAuth(appID, installationID int64, privateKey []byte) (*GitHubAppAuth, error) {
+	key, err := parseRSAPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitHubAppAuth{
+		AppID:          appID,
+		InstallationID: installationID,
+		PrivateKey:     key,
+		BaseURL:        DefaultGitHubAPIURL,
+		Client:         http.DefaultClient,
+	}, nil
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM-encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.New("cannot parse private key")
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// AuthMethod implements Auth.
+func (a *GitHubAppAuth) AuthMethod(ctx context.Context) (gittransport.AuthMethod, error) {
+	token, err := a.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return TokenAuth{Token: token}.AuthMethod(ctx)
+}
+
+// Token returns an installation token, requesting a new one if the current one
+// is about to expire.
+func (a *GitHubAppAuth) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && time.Until(a.expires) > gitHubTokenSlack {
+		return a.token, nil
+	}
+
+	token, expires, err := a.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	a.token = token
+	a.expires = expires
+	return token, nil
+}
+
+func (a *GitHubAppAuth) requestToken(ctx context.Context) (string, time.Time, error) {
+	jwt, err := a.signJWT(time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	baseURL := a.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultGitHubAPIURL
+	}
+
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", a.InstallationID)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimSuffix(baseURL, "/")+path, nil)
+	if err != nil {
+		return "", time.Time{}, errors.Wrap(err, "cannot create request")
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, errors.Wrap(err, "cannot request installation token")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var ghErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&ghErr)
+		return "", time.Time{}, fmt.Errorf(
+			"GitHub returned %s for installation token: %s", resp.Status, ghErr.Message)
+	}
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", time.Time{}, errors.Wrap(err, "cannot decode installation token")
+	}
+
+	if body.Token == "" {
+		return "", time.Time{}, errors.New("GitHub returned an empty installation token")
+	}
+
+	return body.Token, body.ExpiresAt, nil
+}
+
+// signJWT creates the JSON Web Token that authenticates the app itself. GitHub
+// allows it to live for at most 10 minutes, and the issued time is backdated
+// to allow for clock drift.
+func (a *GitHubAppAuth) signJWT(now time.Time) (string, error) {
+	if a.PrivateKey == nil {
+		return "", errors.New("GitHub App has no private key")
+	}
+
+	header := `{"alg":"RS256","typ":"JWT"}`
+	claims, err := json.Marshal(struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}{
+		IssuedAt:  now.Add(-time.Minute).Unix(),
+		ExpiresAt: now.Add(9 * time.Minute).Unix(),
+		Issuer:    strconv.FormatInt(a.AppID, 10),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "cannot encode JWT claims")
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+		base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", errors.Wrap(err, "cannot sign JWT")
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
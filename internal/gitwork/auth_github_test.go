@@ -0,0 +1,232 @@
+package gitwork
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func newTestRSAKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+}
+
+// newTestGitHubApp creates a GitHubAppAuth for app 7 and installation 42
+// against a fake GitHub that checks the app's JWTs. The returned function
+// reports how many tokens the fake GitHub has handed out.
+func newTestGitHubApp(t *testing.T, expiresIn time.Duration) (*GitHubAppAuth, func() int) {
+	t.Helper()
+
+	key, keyPEM := newTestRSAKey(t)
+
+	var issued int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/app/installations/42/access_tokens" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if err := checkJWT(&key.PublicKey, r.Header.Get("Authorization")); err != nil {
+			t.Error("bad JWT:", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Bad credentials"})
+			return
+		}
+
+		issued++
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":      fmt.Sprintf("token%d", issued),
+			"expires_at": time.Now().Add(expiresIn).UTC().Format(time.RFC3339),
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	auth, err := NewGitHubAppAuth(7, 42, keyPEM)
+	if err != nil {
+		t.Fatal("NewGitHubAppAuth:", err)
+	}
+	auth.BaseURL = srv.URL
+	auth.Client = srv.Client()
+
+	return auth, func() int { return issued }
+}
+
+// checkJWT checks that the Authorization header holds a JWT for app 7 that is
+// signed by the given key and valid right now.
+func checkJWT(key *rsa.PublicKey, header string) error {
+	jwt := strings.TrimPrefix(header, "Bearer ")
+	parts := strings.Split(jwt, ".")
+	if jwt == header || len(parts) != 3 {
+		return fmt.Errorf("Authorization %q is not a bearer JWT", header)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return err
+	}
+
+	var jwtHeader struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &jwtHeader); err != nil {
+		return err
+	}
+	if jwtHeader.Alg != "RS256" {
+		return fmt.Errorf("alg is %q, want RS256", jwtHeader.Alg)
+	}
+
+	var claims struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	switch {
+	case claims.Issuer != "7":
+		return fmt.Errorf("iss is %q, want 7", claims.Issuer)
+	case claims.IssuedAt > now:
+		return fmt.Errorf("iat is %d seconds in the future", claims.IssuedAt-now)
+	case claims.ExpiresAt <= now:
+		return fmt.Errorf("exp is %d seconds in the past", now-claims.ExpiresAt)
+	case claims.ExpiresAt-claims.IssuedAt > 10*60:
+		// GitHub rejects JWTs that live longer than 10 minutes.
+		return fmt.Errorf("JWT lives for %d seconds", claims.ExpiresAt-claims.IssuedAt)
+	}
+
+	return nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func TestGitHubAppAuth(t *testing.T) {
+	ctx := context.Background()
+	auth, issued := newTestGitHubApp(t, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		token, err := auth.Token(ctx)
+		if err != nil {
+			t.Fatal("Token:", err)
+		}
+		if token != "token1" {
+			t.Errorf("Token = %q, want token1", token)
+		}
+	}
+	if n := issued(); n != 1 {
+		t.Errorf("requested %d tokens, want 1 that is reused", n)
+	}
+
+	method, err := auth.AuthMethod(ctx)
+	if err != nil {
+		t.Fatal("AuthMethod:", err)
+	}
+	basic, ok := method.(*githttp.BasicAuth)
+	if !ok || basic.Password != "token1" || basic.Username != DefaultTokenUsername {
+		t.Errorf("AuthMethod = %#v, want basic auth with token1", method)
+	}
+}
+
+func TestGitHubAppAuthRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	// Tokens that expire within gitHubTokenSlack are never reused.
+	auth, issued := newTestGitHubApp(t, gitHubTokenSlack/2)
+
+	for _, want := range []string{"token1", "token2"} {
+		token, err := auth.Token(ctx)
+		if err != nil {
+			t.Fatal("Token:", err)
+		}
+		if token != want {
+			t.Errorf("Token = %q, want %q", token, want)
+		}
+	}
+	if n := issued(); n != 2 {
+		t.Errorf("requested %d tokens, want 2", n)
+	}
+}
+
+func TestGitHubAppAuthError(t *testing.T) {
+	_, keyPEM := newTestRSAKey(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Not Found"})
+	}))
+	t.Cleanup(srv.Close)
+
+	auth, err := NewGitHubAppAuth(7, 42, keyPEM)
+	if err != nil {
+		t.Fatal("NewGitHubAppAuth:", err)
+	}
+	auth.BaseURL = srv.URL
+	auth.Client = srv.Client()
+
+	_, err = auth.Token(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Not Found") {
+		t.Errorf("Token error = %v, want GitHub's message", err)
+	}
+}
+
+func TestNewGitHubAppAuthKeys(t *testing.T) {
+	key, pkcs1 := newTestRSAKey(t)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	for name, data := range map[string][]byte{"PKCS #1": pkcs1, "PKCS #8": pkcs8} {
+		auth, err := NewGitHubAppAuth(7, 42, data)
+		if err != nil {
+			t.Errorf("%s key: %v", name, err)
+			continue
+		}
+		if !auth.PrivateKey.Equal(key) {
+			t.Errorf("%s key was parsed into a different key", name)
+		}
+	}
+
+	if _, err := NewGitHubAppAuth(7, 42, []byte("not a key")); err == nil {
+		t.Error("NewGitHubAppAuth of garbage succeeded")
+	}
+}
@@ -0,0 +1,37 @@
+package gitwork
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSSHKeyAuthRetries(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "id_rsa")
+	auth := NewSSHKeyAuth("git", path, "")
+
+	if _, err := auth.AuthMethod(ctx); err == nil {
+		t.Fatal("AuthMethod succeeded without a key file")
+	}
+
+	_, keyPEM := newTestRSAKey(t)
+	if err := os.WriteFile(path, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	method, err := auth.AuthMethod(ctx)
+	if err != nil {
+		t.Fatal("AuthMethod after the key file was written:", err)
+	}
+
+	// The key is only read once it loads.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	again, err := auth.AuthMethod(ctx)
+	if err != nil || again != method {
+		t.Errorf("AuthMethod = %v, %v, want the loaded key", again, err)
+	}
+}
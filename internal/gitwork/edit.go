@@ -0,0 +1,210 @@
+package gitwork
+
+import (
+	"os"
+	"sort"
+
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/pkg/errors"
+
+	gitobject "github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ErrNoChanges is returned by Repository.Edit if the edit leaves every file
+// the same as in HEAD.
+var ErrNoChanges = errors.New("no changes to commit")
+
+// EditFunc is called by Repository.Edit to make changes to files. It returns
+// the title and body of the commit that records the changes.
+type EditFunc func(edit *Edit) (title, body string, err error)
+
+// Edit is a set of changes to files made within Repository.Edit. Changes are
+// kept in memory until the edit is committed, so an edit that fails halfway
+// never leaves a half-written file behind.
+type Edit struct {
+	repo    *Repository
+	changes map[string][]byte // nil means removed
+}
+
+// ReadFile reads the file at the given path, including the changes made so
+// far in the edit. os.ErrNotExist is returned if the file doesn't exist.
+func (e *Edit) ReadFile(path string) ([]byte, error) {
+	path = cleanPath(path)
+
+	if data, ok := e.changes[path]; ok {
+		if data == nil {
+			return nil, os.ErrNotExist
+		}
+		return data, nil
+	}
+
+	return e.repo.ReadFile(path)
+}
+
+// WriteFile replaces the content of the file at the given path, creating it
+// if needed.
+func (e *Edit) WriteFile(path string, data []byte) {
+	if data == nil {
+		data = []byte{}
+	}
+	e.changes[cleanPath(path)] = data
+}
+
+// Remove removes the file at the given path.
+func (e *Edit) Remove(path string) {
+	e.changes[cleanPath(path)] = nil
+}
+
+// Paths returns the paths of the files changed in the edit.
+func (e *Edit) Paths() []string {
+	paths := make([]string, 0, len(e.changes))
+	for path := range e.changes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Edit makes changes to files and commits them as one commit. f is called to
+// make the changes; if it returns an error, then nothing is written. Once f
+// returns, the changed files are written, staged and committed. If any of
+// that fails, then the changed files and their index entries are restored to
+// how they were in HEAD before the error is returned.
+//
+// Edits on the same repository are serialized.
+func (r *Repository) Edit(f EditFunc) (CommitHash, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	edit := &Edit{
+		repo:    r,
+		changes: make(map[string][]byte),
+	}
+
+	title, body, err := f(edit)
+	if err != nil {
+		return CommitHash{}, err
+	}
+
+	return edit.commit(title, body)
+}
+
+// editBackup is the content of a file in the worktree before it was changed.
+// data is nil if the file didn't exist.
+type editBackup struct {
+	path string
+	data []byte
+}
+
+func (e *Edit) commit(title, body string) (hash CommitHash, err error) {
+	head, err := e.repo.Head()
+	if err != nil {
+		return CommitHash{}, errors.Wrap(err, "cannot get HEAD")
+	}
+
+	headCommit, err := e.repo.CommitObject(head.Hash())
+	if err != nil {
+		return CommitHash{}, errors.Wrap(err, "cannot get HEAD commit")
+	}
+
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return CommitHash{}, errors.Wrap(err, "cannot get HEAD tree")
+	}
+
+	changed, err := e.changedFrom(headTree)
+	if err != nil {
+		return CommitHash{}, err
+	}
+	if len(changed) == 0 {
+		return CommitHash{}, ErrNoChanges
+	}
+
+	fs := e.repo.FS()
+	worktree := e.repo.Worktree()
+
+	var backups []editBackup
+	defer func() {
+		if err == nil {
+			return
+		}
+		if rollbackErr := e.rollback(backups, head.Hash()); rollbackErr != nil {
+			err = errors.Wrapf(err, "%v (after rollback failed)", rollbackErr)
+		}
+	}()
+
+	for _, path := range changed {
+		old, readErr := util.ReadFile(fs, path)
+		if readErr != nil && !os.IsNotExist(readErr) {
+			return CommitHash{}, errors.Wrapf(readErr, "cannot back up %s", path)
+		}
+		if readErr == nil && old == nil {
+			old = []byte{}
+		}
+		backups = append(backups, editBackup{path, old})
+
+		data := e.changes[path]
+		if data == nil {
+			if _, err = worktree.Remove(path); err != nil {
+				return CommitHash{}, errors.Wrapf(err, "cannot remove %s", path)
+			}
+			continue
+		}
+
+		if err = writeFile(e.repo, path, data); err != nil {
+			return CommitHash{}, err
+		}
+
+		if _, err = worktree.Add(path); err != nil {
+			return CommitHash{}, errors.Wrapf(err, "cannot stage %s", path)
+		}
+	}
+
+	hash, err = e.repo.commit(title, body)
+	if err != nil {
+		return CommitHash{}, errors.Wrap(err, "cannot commit")
+	}
+
+	return hash, nil
+}
+
+// changedFrom returns the paths of the files that the edit actually changes
+// compared to the given tree.
+func (e *Edit) changedFrom(tree *gitobject.Tree) ([]string, error) {
+	var changed []string
+	for _, path := range e.Paths() {
+		old, err := treeFile(tree, path)
+		if err != nil {
+			return nil, err
+		}
+		if !sameFile(old, e.changes[path]) {
+			changed = append(changed, path)
+		}
+	}
+	return changed, nil
+}
+
+// rollback restores the backed up files and resets the index to the given
+// commit.
+func (e *Edit) rollback(backups []editBackup, head CommitHash) error {
+	fs := e.repo.FS()
+
+	for _, backup := range backups {
+		if backup.data == nil {
+			if err := fs.Remove(backup.path); err != nil && !os.IsNotExist(err) {
+				return errors.Wrapf(err, "cannot remove %s", backup.path)
+			}
+			continue
+		}
+
+		if err := writeFile(e.repo, backup.path, backup.data); err != nil {
+			return err
+		}
+	}
+
+	return e.repo.Worktree().Reset(&git.ResetOptions{
+		Commit: head,
+		Mode:   git.MixedReset,
+	})
+}
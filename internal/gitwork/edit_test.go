@@ -0,0 +1,193 @@
+package gitwork
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+// failingStorer is a storer that refuses to store commits while fail is set.
+type failingStorer struct {
+	storage.Storer
+	fail bool
+}
+
+func (s *failingStorer) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	if s.fail && obj.Type() == plumbing.CommitObject {
+		return plumbing.ZeroHash, errors.New("disk full")
+	}
+	return s.Storer.SetEncodedObject(obj)
+}
+
+func TestEdit(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a", "b.txt": "b"})
+
+	repo, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := repo.Edit(func(edit *Edit) (string, string, error) {
+		edit.WriteFile("a.txt", []byte("a2"))
+		edit.WriteFile("c/d.txt", []byte("d"))
+		edit.Remove("b.txt")
+		return "Change files", "", nil
+	})
+	if err != nil {
+		t.Fatal("Edit:", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Hash() != hash {
+		t.Errorf("HEAD = %s, want the edit's commit %s", head.Hash(), hash)
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.Message != "Change files" {
+		t.Errorf("commit message = %q", commit.Message)
+	}
+
+	// The changes are committed without anyone calling Add.
+	for path, want := range map[string]string{"a.txt": "a2", "c/d.txt": "d"} {
+		file, err := commit.File(path)
+		if err != nil {
+			t.Errorf("%s is not committed: %v", path, err)
+			continue
+		}
+		if got, _ := file.Contents(); got != want {
+			t.Errorf("committed %s = %q, want %q", path, got, want)
+		}
+		if file.Mode != filemode.Regular {
+			t.Errorf("committed %s with mode %v, want a regular file", path, file.Mode)
+		}
+	}
+	if _, err := commit.File("b.txt"); err == nil {
+		t.Error("removed b.txt is still committed")
+	}
+
+	assertClean(t, repo)
+
+	_, err = repo.Edit(func(edit *Edit) (string, string, error) {
+		edit.WriteFile("a.txt", []byte("a2"))
+		return "Change nothing", "", nil
+	})
+	if !errors.Is(err, ErrNoChanges) {
+		t.Errorf("Edit without changes = %v, want ErrNoChanges", err)
+	}
+}
+
+func TestEditRollback(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a", "b.txt": "b"})
+
+	var storer *failingStorer
+	repo, err := Clone(ctx, remote.URL(), false, nil, func() (storage.Storer, billy.Filesystem, error) {
+		inner, fs, err := InMemory()()
+		storer = &failingStorer{Storer: inner}
+		return storer, fs, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edit := func(edit *Edit) (string, string, error) {
+		edit.WriteFile("a.txt", []byte("a2"))
+		edit.WriteFile("c.txt", []byte("c"))
+		edit.Remove("b.txt")
+		return "Change files", "", nil
+	}
+
+	tests := []struct {
+		name string
+		edit EditFunc
+		fail bool
+		want string
+	}{
+		{
+			name: "EditFunc error",
+			edit: func(e *Edit) (string, string, error) {
+				edit(e)
+				return "", "", errors.New("changed my mind")
+			},
+			want: "changed my mind",
+		},
+		{
+			name: "commit error",
+			edit: edit,
+			fail: true,
+			want: "disk full",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storer.fail = test.fail
+			defer func() { storer.fail = false }()
+
+			_, err := repo.Edit(test.edit)
+			if err == nil {
+				t.Fatal("Edit succeeded")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Edit error = %v, want %q", err, test.want)
+			}
+
+			newHead, err := repo.Head()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if newHead.Hash() != head.Hash() {
+				t.Errorf("HEAD moved from %s to %s", head.Hash(), newHead.Hash())
+			}
+
+			if got := readFile(t, repo, "a.txt"); got != "a" {
+				t.Errorf("a.txt = %q, want a", got)
+			}
+			if got := readFile(t, repo, "b.txt"); got != "b" {
+				t.Errorf("b.txt = %q, want b", got)
+			}
+			if _, err := repo.FS().Stat("c.txt"); !os.IsNotExist(err) {
+				t.Errorf("c.txt was left behind: %v", err)
+			}
+
+			assertClean(t, repo)
+		})
+	}
+
+	// The repository is still usable after the failures.
+	if _, err := repo.Edit(edit); err != nil {
+		t.Fatal("Edit after rollback:", err)
+	}
+	assertClean(t, repo)
+}
+
+func assertClean(t *testing.T, repo *Repository) {
+	t.Helper()
+
+	status, err := repo.Worktree().Status()
+	if err != nil {
+		t.Fatal("Status:", err)
+	}
+	if !status.IsClean() {
+		t.Errorf("worktree is not clean:\n%s", status)
+	}
+}
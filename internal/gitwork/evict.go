@@ -0,0 +1,286 @@
+package gitwork
+
+import (
+	"context"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/pkg/errors"
+)
+
+// minEvictIdle is how long a workspace must go unused before it may be
+// evicted to stay within the disk budget, so that workspaces in use are left
+// alone.
+const minEvictIdle = 5 * time.Minute
+
+// ownerFile is the file in the .git directory of a workspace that marks it as
+// used by a pool. Only workspaces with it are ever evicted, so that a pool
+// never deletes a checkout that merely happens to be in its root path.
+const ownerFile = "gitwork-pool"
+
+// ErrInUse is returned when evicting a workspace that is in use; see
+// PooledRepository.Acquire.
+var ErrInUse = errors.New("workspace is in use")
+
+// Evict removes the workspace at the given path from the pool and deletes it
+// from the disk. Unlike Collect, it doesn't check whether the workspace is
+// safe to evict, but it refuses to evict workspaces that are in use or that
+// weren't opened by a pool.
+func (p *Pool) Evict(path string) error {
+	path = poolPath(path)
+
+	if !p.owns(path) {
+		return errors.Errorf("workspace %s does not belong to the pool", path)
+	}
+
+	p.repoMu.Lock()
+	repo, ok := p.repositories[path]
+	if ok && repo.users > 0 {
+		p.repoMu.Unlock()
+		return ErrInUse
+	}
+	delete(p.repositories, path)
+	delete(p.stores, path)
+	p.repoMu.Unlock()
+
+	if ok {
+		// Wait for any edit in progress to finish before pulling the files
+		// out from under it.
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+	}
+
+	if err := os.RemoveAll(filepath.Join(p.RootPath, path)); err != nil {
+		return errors.Wrapf(err, "cannot delete workspace %s", path)
+	}
+
+	p.indexMu.Lock()
+	defer p.indexMu.Unlock()
+
+	delete(p.index, path)
+	p.scheduleSave()
+	return nil
+}
+
+// owns returns true if the workspace at the given path is where Clone puts
+// workspaces and, if it's on the disk, has been marked as used by a pool.
+func (p *Pool) owns(path string) bool {
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 1 && strings.HasPrefix(parts[0], "repo-"):
+	case len(parts) == 2 && parts[0] != MirrorDir && parts[0] != ".." && parts[1] != "..":
+	default:
+		return false
+	}
+
+	dir := filepath.Join(p.RootPath, filepath.FromSlash(path))
+	if _, err := os.Lstat(dir); os.IsNotExist(err) {
+		// Workspaces that aren't on the disk leave nothing to delete.
+		return true
+	}
+
+	_, err := os.Stat(filepath.Join(dir, git.GitDirName, ownerFile))
+	return err == nil
+}
+
+// markOwned marks the workspace at the given path as used by the pool; see
+// ownerFile. Workspaces that aren't on the disk are left alone.
+func (p *Pool) markOwned(path string) {
+	gitDir := filepath.Join(p.RootPath, filepath.FromSlash(path), git.GitDirName)
+	if stat, err := os.Stat(gitDir); err != nil || !stat.IsDir() {
+		return
+	}
+
+	marker := filepath.Join(gitDir, ownerFile)
+	if _, err := os.Stat(marker); err == nil {
+		return
+	}
+
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		log.Printf("gitwork: cannot mark workspace %s: %v", path, err)
+	}
+}
+
+// Collect evicts the workspaces that have been idle for longer than MaxIdle,
+// then the least recently used workspaces until the pool fits in DiskBudget.
+// Workspaces with unpushed commits are never evicted, and neither are those
+// that Keep wants to keep, that are in use or that can't be opened. The paths
+// of the evicted workspaces are returned.
+func (p *Pool) Collect(ctx context.Context) ([]string, error) {
+	infos := p.Workspaces()
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].LastUsedAt.Before(infos[j].LastUsedAt)
+	})
+
+	sizes := make(map[string]int64, len(infos))
+	var total int64
+	for _, info := range infos {
+		size, err := dirSize(filepath.Join(p.RootPath, info.Path))
+		if err != nil {
+			return nil, err
+		}
+		sizes[info.Path] = size
+		total += size
+	}
+
+	now := time.Now()
+	var evicted []string
+	var errs []error
+
+	for _, info := range infos {
+		if err := ctx.Err(); err != nil {
+			return evicted, err
+		}
+
+		idle := now.Sub(info.LastUsedAt)
+		expired := p.MaxIdle > 0 && idle > p.MaxIdle
+		overBudget := p.DiskBudget > 0 && total > p.DiskBudget && idle > minEvictIdle
+		if !expired && !overBudget {
+			continue
+		}
+
+		if !p.owns(info.Path) {
+			continue
+		}
+
+		keep, err := p.keep(ctx, info)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "cannot check workspace %s", info.Path))
+			continue
+		}
+		if keep {
+			continue
+		}
+
+		if err := p.Evict(info.Path); err != nil {
+			if !errors.Is(err, ErrInUse) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		evicted = append(evicted, info.Path)
+		total -= sizes[info.Path]
+	}
+
+	if len(errs) > 0 {
+		return evicted, errs[0]
+	}
+
+	return evicted, nil
+}
+
+// keep returns true if the workspace must not be evicted.
+func (p *Pool) keep(ctx context.Context, info WorkspaceInfo) (bool, error) {
+	p.repoMu.Lock()
+	pooled, ok := p.repositories[info.Path]
+	p.repoMu.Unlock()
+
+	var repo *Repository
+	if ok {
+		if pooled.inUse() {
+			return true, nil
+		}
+		repo = pooled.Repository
+	} else {
+		// Open the repository without registering it, which would mark it
+		// as used.
+		r, err := Open(p.store(info.Path))
+		if err != nil {
+			// A workspace that can't be opened may still hold someone's
+			// work, so leave it for a human to look at.
+			log.Printf("gitwork: keeping workspace %s that cannot be opened: %v", info.Path, err)
+			return true, nil
+		}
+		repo = r
+	}
+
+	unpushed, err := repo.UnpushedCommits()
+	if err != nil {
+		return false, err
+	}
+	if len(unpushed) > 0 {
+		return true, nil
+	}
+
+	if p.Keep != nil {
+		return p.Keep(ctx, info)
+	}
+
+	return info.PullRequest != 0, nil
+}
+
+// StartJanitor starts a goroutine that calls Collect every interval until ctx
+// is done. Errors are logged. The janitor refuses to start if RootPath is the
+// shared temporary directory, since other programs keep files there too.
+func (p *Pool) StartJanitor(ctx context.Context, interval time.Duration) {
+	if isTempDir(p.RootPath) {
+		log.Println("gitwork: not collecting workspaces in the shared temporary directory", p.RootPath)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			evicted, err := p.Collect(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Println("gitwork: cannot collect workspaces:", err)
+			}
+			if len(evicted) > 0 {
+				log.Printf("gitwork: evicted %d workspaces", len(evicted))
+			}
+		}
+	}()
+}
+
+// isTempDir returns true if dir is the system's temporary directory.
+func isTempDir(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	tmp, err := filepath.Abs(os.TempDir())
+	if err != nil {
+		return false
+	}
+	return dir == tmp
+}
+
+// dirSize returns the total size of the files in the directory.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "cannot measure %s", dir)
+	}
+	return size, nil
+}
@@ -0,0 +1,121 @@
+package gitwork
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestCollect(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+	root := t.TempDir()
+
+	pool := newTestPool(t, root, remote)
+	for _, path := range []string{"g/idle", "g/used", "g/broken"} {
+		if _, err := pool.Clone(ctx, false, path); err != nil {
+			t.Fatal("Clone:", err)
+		}
+	}
+
+	// A checkout that the pool didn't make.
+	if _, err := git.PlainInit(filepath.Join(root, "g", "foreign"), false); err != nil {
+		t.Fatal(err)
+	}
+	// A workspace that can't be opened anymore.
+	if err := os.Remove(filepath.Join(root, "g", "broken", git.GitDirName, "HEAD")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart the pool so that it finds the workspaces on the disk.
+	pool = newTestPool(t, root, remote)
+	pool.MaxIdle = 1
+
+	used, err := pool.Open("g/used")
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	used.Acquire()
+
+	if err := pool.Evict("g/used"); !errors.Is(err, ErrInUse) {
+		t.Errorf("Evict of a workspace in use = %v, want ErrInUse", err)
+	}
+	if err := pool.Evict("g/foreign"); err == nil {
+		t.Error("Evict of a foreign checkout succeeded")
+	}
+
+	evicted, err := pool.Collect(ctx)
+	if err != nil {
+		t.Fatal("Collect:", err)
+	}
+	if !equalStrings(evicted, []string{"g/idle"}) {
+		t.Errorf("evicted %q, want only g/idle", evicted)
+	}
+
+	for path, exists := range map[string]bool{
+		"g/idle":    false,
+		"g/used":    true,
+		"g/broken":  true,
+		"g/foreign": true,
+	} {
+		_, err := os.Stat(filepath.Join(root, path))
+		if (err == nil) != exists {
+			t.Errorf("%s exists = %v, want %v", path, err == nil, exists)
+		}
+	}
+
+	used.Release()
+
+	evicted, err = pool.Collect(ctx)
+	if err != nil {
+		t.Fatal("Collect:", err)
+	}
+	if !equalStrings(evicted, []string{"g/used"}) {
+		t.Errorf("evicted %q after Release, want only g/used", evicted)
+	}
+}
+
+func TestCloneAcquired(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+	pool := newTestPool(t, t.TempDir(), remote)
+
+	// Both a new workspace and one that's already open come back acquired.
+	for i := 0; i < 2; i++ {
+		if _, err := pool.CloneAcquired(ctx, false, "g/user"); err != nil {
+			t.Fatal("CloneAcquired:", err)
+		}
+	}
+
+	repo, err := pool.Open("g/user")
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := pool.Evict("g/user"); !errors.Is(err, ErrInUse) {
+			t.Fatalf("Evict with %d users = %v, want ErrInUse", 2-i, err)
+		}
+		repo.Release()
+	}
+
+	if err := pool.Evict("g/user"); err != nil {
+		t.Fatal("Evict after Release:", err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
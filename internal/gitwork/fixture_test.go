@@ -0,0 +1,146 @@
+package gitwork
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+
+	gitconfig "github.com/go-git/go-git/v5/config"
+	gitplumbing "github.com/go-git/go-git/v5/plumbing"
+)
+
+// testRemote is a bare repository on disk that tests use as the remote. Its
+// default branch is master.
+type testRemote struct {
+	t    *testing.T
+	path string
+}
+
+// newTestRemote creates a bare repository with an initial commit holding the
+// given files.
+func newTestRemote(t *testing.T, files map[string]string) *testRemote {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "remote.git")
+	if _, err := git.PlainInit(path, true); err != nil {
+		t.Fatal("cannot init remote:", err)
+	}
+
+	repo, err := Init(InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{path},
+	})
+	if err != nil {
+		t.Fatal("cannot create origin remote:", err)
+	}
+
+	remote := &testRemote{t: t, path: path}
+	remote.commit(repo, "Initial commit", files)
+	return remote
+}
+
+// URL returns the URL of the remote.
+func (r *testRemote) URL() string {
+	return r.path
+}
+
+// Push commits the given files onto the remote's default branch as someone
+// else would. An empty content deletes the file.
+func (r *testRemote) Push(title string, files map[string]string) CommitHash {
+	r.t.Helper()
+
+	repo, err := Clone(context.Background(), r.path, false, nil, InMemory())
+	if err != nil {
+		r.t.Fatal("cannot clone remote:", err)
+	}
+
+	return r.commit(repo, title, files)
+}
+
+func (r *testRemote) commit(repo *Repository, title string, files map[string]string) CommitHash {
+	r.t.Helper()
+
+	for name, content := range files {
+		if content == "" {
+			if _, err := repo.Worktree().Remove(name); err != nil {
+				r.t.Fatalf("cannot remove %s: %v", name, err)
+			}
+			continue
+		}
+		if err := util.WriteFile(repo.FS(), name, []byte(content), 0644); err != nil {
+			r.t.Fatalf("cannot write %s: %v", name, err)
+		}
+		if err := repo.Add(name); err != nil {
+			r.t.Fatalf("cannot add %s: %v", name, err)
+		}
+	}
+
+	hash, err := repo.Commit(title, "")
+	if err != nil {
+		r.t.Fatal("cannot commit:", err)
+	}
+
+	err = repo.Repository.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{"refs/heads/master:refs/heads/master"},
+	})
+	if err != nil {
+		r.t.Fatal("cannot push to remote:", err)
+	}
+
+	return hash
+}
+
+// Head returns the commit that the given branch of the remote is on, or a zero
+// hash if it doesn't exist.
+func (r *testRemote) Head(branch string) CommitHash {
+	r.t.Helper()
+
+	repo, err := git.PlainOpen(r.path)
+	if err != nil {
+		r.t.Fatal("cannot open remote:", err)
+	}
+
+	ref, err := repo.Reference(gitplumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return CommitHash{}
+	}
+	return ref.Hash()
+}
+
+// readFile reads a file from the repository's worktree, failing the test if it
+// cannot be read.
+func readFile(t *testing.T, repo *Repository, path string) string {
+	t.Helper()
+
+	b, err := repo.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read %s: %v", path, err)
+	}
+	return string(b)
+}
+
+// newTestPool creates a pool at root over the remote. The pool's index is
+// saved before the test's temporary directories are removed.
+func newTestPool(t *testing.T, root string, remote *testRemote) *Pool {
+	t.Helper()
+
+	pool, err := NewPool(root, remote.URL())
+	if err != nil {
+		t.Fatal("NewPool:", err)
+	}
+	t.Cleanup(func() {
+		if err := pool.Flush(); err != nil {
+			t.Error("Flush:", err)
+		}
+	})
+	return pool
+}
@@ -0,0 +1,284 @@
+package gitwork
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/pkg/errors"
+)
+
+// IndexFile is the name of the file in the pool's root path that the pool
+// keeps its workspace metadata in.
+const IndexFile = "gitwork.json"
+
+// WorkspaceInfo is the metadata that the pool keeps about a workspace.
+type WorkspaceInfo struct {
+	// Path is the path of the workspace relative to the pool's root path.
+	Path string `json:"path"`
+	// Owner describes who the workspace belongs to. It is set by the user of
+	// the pool using PooledRepository.SetOwner.
+	Owner string `json:"owner,omitempty"`
+	// Branch is the branch that the workspace is checked out on.
+	Branch string `json:"branch"`
+	// Upstream is the upstream commit that the workspace was last synced
+	// with.
+	Upstream string `json:"upstream,omitempty"`
+	// PullRequest is the number of the pull request made from the branch, if
+	// any. It is set using PooledRepository.SetPullRequest.
+	PullRequest int `json:"pullRequest,omitempty"`
+	// CreatedAt is when the workspace was first seen by the pool.
+	CreatedAt time.Time `json:"createdAt"`
+	// LastUsedAt is when the workspace was last handed out by the pool.
+	LastUsedAt time.Time `json:"lastUsedAt"`
+}
+
+type poolIndex struct {
+	Workspaces []WorkspaceInfo `json:"workspaces"`
+}
+
+// loadIndex rebuilds the pool's index from the index file and the
+// workspaces found in the root path. Workspaces that are in the index file
+// but are gone from the disk are dropped, and workspaces that are on the disk
+// but not in the index file are added.
+func (p *Pool) loadIndex() error {
+	p.index = make(map[string]*WorkspaceInfo)
+
+	b, err := os.ReadFile(filepath.Join(p.RootPath, IndexFile))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "cannot read pool index")
+	}
+
+	if err == nil {
+		var index poolIndex
+		if err := json.Unmarshal(b, &index); err != nil {
+			return errors.Wrap(err, "cannot decode pool index")
+		}
+
+		for i := range index.Workspaces {
+			info := index.Workspaces[i]
+			p.index[info.Path] = &info
+		}
+	}
+
+	found, err := p.scanWorkspaces()
+	if err != nil {
+		return err
+	}
+
+	for path := range p.index {
+		if _, ok := found[path]; !ok {
+			delete(p.index, path)
+		}
+	}
+
+	for path, modTime := range found {
+		if _, ok := p.index[path]; !ok {
+			p.index[path] = &WorkspaceInfo{
+				Path:       path,
+				Branch:     p.BranchName(path),
+				CreatedAt:  modTime,
+				LastUsedAt: modTime,
+			}
+		}
+	}
+
+	return p.saveIndex()
+}
+
+// scanWorkspaces returns the paths of the workspaces in the root path,
+// mapped to when they were last modified. Only the places that Clone puts
+// workspaces in are looked at: the repo-* directories made for an empty
+// dstDir, and directories two levels deep such as <guild>/<user>. Other git
+// checkouts that happen to be in the root path are left alone.
+func (p *Pool) scanWorkspaces() (map[string]time.Time, error) {
+	entries, err := os.ReadDir(p.RootPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot scan pool for workspaces")
+	}
+
+	found := make(map[string]time.Time)
+
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == MirrorDir {
+			continue
+		}
+
+		if strings.HasPrefix(entry.Name(), "repo-") {
+			p.scanWorkspace(found, entry.Name())
+			continue
+		}
+
+		subentries, err := os.ReadDir(filepath.Join(p.RootPath, entry.Name()))
+		if err != nil {
+			// Skip unreadable directories instead of failing the whole pool.
+			continue
+		}
+
+		for _, subentry := range subentries {
+			if subentry.IsDir() {
+				p.scanWorkspace(found, path.Join(entry.Name(), subentry.Name()))
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// scanWorkspace adds the directory at the given path to found if it's a git
+// checkout.
+func (p *Pool) scanWorkspace(found map[string]time.Time, dir string) {
+	stat, err := os.Stat(filepath.Join(p.RootPath, filepath.FromSlash(dir), git.GitDirName))
+	if err == nil && stat.IsDir() {
+		found[dir] = stat.ModTime()
+	}
+}
+
+// saveIndex writes the index into the index file. The index lock must be
+// held.
+func (p *Pool) saveIndex() error {
+	index := poolIndex{
+		Workspaces: make([]WorkspaceInfo, 0, len(p.index)),
+	}
+	for _, info := range p.index {
+		index.Workspaces = append(index.Workspaces, *info)
+	}
+	sort.Slice(index.Workspaces, func(i, j int) bool {
+		return index.Workspaces[i].Path < index.Workspaces[j].Path
+	})
+
+	b, err := json.MarshalIndent(index, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "cannot encode pool index")
+	}
+
+	// Write to a temporary file first so that a crash never leaves a
+	// half-written index behind.
+	tmp, err := os.CreateTemp(p.RootPath, IndexFile+".*")
+	if err != nil {
+		return errors.Wrap(err, "cannot create pool index")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "cannot write pool index")
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "cannot write pool index")
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(p.RootPath, IndexFile)); err != nil {
+		return errors.Wrap(err, "cannot replace pool index")
+	}
+
+	return nil
+}
+
+// indexSaveDelay is how long the pool waits after a change to its index
+// before saving it, so that a burst of changes is saved in one write.
+const indexSaveDelay = 10 * time.Second
+
+// updateInfo updates the metadata of the workspace at the given path. The
+// workspace is added to the index if it's not there yet. The index is saved
+// in the background shortly after; see Flush.
+func (p *Pool) updateInfo(path string, f func(*WorkspaceInfo)) {
+	p.indexMu.Lock()
+	defer p.indexMu.Unlock()
+
+	info, ok := p.index[path]
+	if !ok {
+		now := time.Now()
+		info = &WorkspaceInfo{
+			Path:       path,
+			Branch:     p.BranchName(path),
+			CreatedAt:  now,
+			LastUsedAt: now,
+		}
+		p.index[path] = info
+	}
+
+	f(info)
+	p.scheduleSave()
+}
+
+// scheduleSave marks the index as changed and saves it after indexSaveDelay
+// unless a save is already scheduled. Failed saves are logged, since the
+// index is rebuilt from the disk on restart anyway. The index lock must be
+// held.
+func (p *Pool) scheduleSave() {
+	p.indexDirty = true
+	if p.indexTimer != nil {
+		return
+	}
+
+	p.indexTimer = time.AfterFunc(indexSaveDelay, func() {
+		if err := p.Flush(); err != nil {
+			log.Println("gitwork: cannot save pool index:", err)
+		}
+	})
+}
+
+// Flush saves the pool's index if it has unsaved changes. The pool saves its
+// index on its own shortly after every change, so Flush is only needed
+// before exiting.
+func (p *Pool) Flush() error {
+	p.indexMu.Lock()
+	defer p.indexMu.Unlock()
+
+	if p.indexTimer != nil {
+		p.indexTimer.Stop()
+		p.indexTimer = nil
+	}
+
+	if !p.indexDirty {
+		return nil
+	}
+
+	if err := p.saveIndex(); err != nil {
+		return err
+	}
+
+	p.indexDirty = false
+	return nil
+}
+
+// Workspaces returns the metadata of all workspaces in the pool, sorted by
+// path.
+func (p *Pool) Workspaces() []WorkspaceInfo {
+	p.indexMu.Lock()
+	defer p.indexMu.Unlock()
+
+	infos := make([]WorkspaceInfo, 0, len(p.index))
+	for _, info := range p.index {
+		infos = append(infos, *info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+
+	return infos
+}
+
+// Info returns the metadata of the workspace at the given path.
+func (p *Pool) Info(path string) (WorkspaceInfo, bool) {
+	p.indexMu.Lock()
+	defer p.indexMu.Unlock()
+
+	info, ok := p.index[poolPath(path)]
+	if !ok {
+		return WorkspaceInfo{}, false
+	}
+	return *info, true
+}
+
+// poolPath normalizes a path relative to the pool's root path so that it can
+// be used as a key.
+func poolPath(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
@@ -0,0 +1,237 @@
+package gitwork
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/pkg/errors"
+
+	gitconfig "github.com/go-git/go-git/v5/config"
+	gitplumbing "github.com/go-git/go-git/v5/plumbing"
+	gittransport "github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// MirrorDir is the directory in the pool's root path that the mirror of the
+// remote is kept in. It's a bare repository.
+const MirrorDir = ".mirror"
+
+// mirrorPath returns the absolute path to the pool's mirror.
+func (p *Pool) mirrorPath() (string, error) {
+	path, err := filepath.Abs(filepath.Join(p.RootPath, MirrorDir))
+	if err != nil {
+		return "", errors.Wrap(err, "cannot resolve mirror path")
+	}
+	return path, nil
+}
+
+// UpdateMirror fetches all branches of the remote into the pool's mirror. The
+// mirror is cloned if it doesn't exist yet. Workspaces that already exist are
+// not affected; they're brought up to date by Sync as usual.
+func (p *Pool) UpdateMirror(ctx context.Context) error {
+	p.mirrorMu.Lock()
+	defer p.mirrorMu.Unlock()
+
+	if p.mirror == nil {
+		cloned, err := p.openMirror(ctx)
+		if err != nil || cloned {
+			return err
+		}
+	}
+
+	method, err := authMethod(ctx, p.Auth)
+	if err != nil {
+		return err
+	}
+
+	err = p.mirror.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: "origin",
+		Auth:       method,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return errors.Wrap(err, "cannot fetch into mirror")
+	}
+
+	return nil
+}
+
+// openMirror opens the mirror, or clones it if it doesn't exist yet. It
+// returns true if the mirror was just cloned. The mirror lock must be held.
+func (p *Pool) openMirror(ctx context.Context) (cloned bool, err error) {
+	path, err := p.mirrorPath()
+	if err != nil {
+		return false, err
+	}
+
+	storage := filesystem.NewStorage(osfs.New(path), cache.NewObjectLRUDefault())
+
+	mirror, err := git.Open(storage, nil)
+	if err == git.ErrRepositoryNotExists {
+		var method gittransport.AuthMethod
+		method, err = authMethod(ctx, p.Auth)
+		if err != nil {
+			return false, err
+		}
+
+		cloned = true
+		mirror, err = git.CloneContext(ctx, storage, nil, &git.CloneOptions{
+			URL:  p.RemoteURL,
+			Auth: method,
+		})
+		if err != nil {
+			os.RemoveAll(path)
+			return false, errors.Wrap(err, "cannot clone mirror")
+		}
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "cannot open mirror")
+	}
+
+	p.mirror = mirror
+	return cloned, nil
+}
+
+// cloneFromMirror creates a workspace at the given directory from the pool's
+// mirror. The workspace borrows the mirror's objects through Git's alternates
+// mechanism, so only the worktree is written. Its origin remote is still the
+// pool's remote, so fetching and pushing work the same as in a cloned
+// workspace.
+func (p *Pool) cloneFromMirror(ctx context.Context, dir string) (*Repository, error) {
+	p.mirrorMu.RLock()
+	if p.mirror == nil {
+		p.mirrorMu.RUnlock()
+		if err := p.UpdateMirror(ctx); err != nil {
+			return nil, err
+		}
+		p.mirrorMu.RLock()
+	}
+	defer p.mirrorMu.RUnlock()
+
+	repo, err := p.initFromMirror(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+func (p *Pool) initFromMirror(dir string) (*Repository, error) {
+	mirrorPath, err := p.mirrorPath()
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := p.mirror.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get mirror HEAD")
+	}
+	branch := head.Name().Short()
+
+	var remoteRefs []*gitplumbing.Reference
+	refs, err := p.mirror.References()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list mirror references")
+	}
+	err = refs.ForEach(func(ref *gitplumbing.Reference) error {
+		if ref.Type() == gitplumbing.HashReference && strings.HasPrefix(ref.Name().String(), "refs/remotes/origin/") {
+			remoteRefs = append(remoteRefs, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot list mirror references")
+	}
+
+	var upstream CommitHash
+	for _, ref := range remoteRefs {
+		if ref.Name() == gitplumbing.NewRemoteReferenceName("origin", branch) {
+			upstream = ref.Hash()
+		}
+	}
+	if upstream.IsZero() {
+		return nil, errors.Errorf("mirror has no branch %q", branch)
+	}
+
+	repo, err := Init(AtDir(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	alternates := filepath.Join(dir, git.GitDirName, "objects", "info")
+	if err := os.MkdirAll(alternates, os.ModePerm); err != nil {
+		return nil, errors.Wrap(err, "cannot create alternates")
+	}
+	err = os.WriteFile(
+		filepath.Join(alternates, "alternates"),
+		[]byte(filepath.Join(mirrorPath, "objects")+"\n"), 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot write alternates")
+	}
+
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{p.RemoteURL},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create origin remote")
+	}
+
+	for _, ref := range remoteRefs {
+		if err := repo.Storer.SetReference(ref); err != nil {
+			return nil, errors.Wrapf(err, "cannot copy reference %q", ref.Name())
+		}
+	}
+
+	branchRef := gitplumbing.NewBranchReferenceName(branch)
+
+	if err := repo.Storer.SetReference(gitplumbing.NewHashReference(branchRef, upstream)); err != nil {
+		return nil, errors.Wrapf(err, "cannot create branch %q", branch)
+	}
+	if err := repo.Storer.SetReference(gitplumbing.NewSymbolicReference(gitplumbing.HEAD, branchRef)); err != nil {
+		return nil, errors.Wrap(err, "cannot set HEAD")
+	}
+
+	err = repo.CreateBranch(&gitconfig.Branch{
+		Name:   branch,
+		Remote: "origin",
+		Merge:  branchRef,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot track upstream for branch %q", branch)
+	}
+
+	if err := repo.resetHard(upstream); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// StartMirror starts a goroutine that calls UpdateMirror every interval until
+// ctx is done. Errors are logged.
+func (p *Pool) StartMirror(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := p.UpdateMirror(ctx); err != nil && ctx.Err() == nil {
+				log.Println("gitwork: cannot update mirror:", err)
+			}
+		}
+	}()
+}
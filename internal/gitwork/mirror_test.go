@@ -0,0 +1,81 @@
+package gitwork
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// TestPoolMirror checks that workspaces made from the mirror work even though
+// their objects live in the mirror, which go-git only partly supports.
+func TestPoolMirror(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+
+	pool := newTestPool(t, t.TempDir(), remote)
+	pool.Mirror = true
+
+	repo, err := pool.Clone(ctx, false, "g/user")
+	if err != nil {
+		t.Fatal("Clone:", err)
+	}
+
+	alternates := filepath.Join(repo.AbsPath(), git.GitDirName, "objects", "info", "alternates")
+	if _, err := os.Stat(alternates); err != nil {
+		t.Fatal("workspace doesn't borrow the mirror's objects:", err)
+	}
+	if got := readFile(t, repo.Repository, "a.txt"); got != "a" {
+		t.Errorf("a.txt = %q, want a", got)
+	}
+
+	// The mirror doesn't have this commit, so syncing must fetch it into the
+	// workspace.
+	remote.Push("Add b", map[string]string{"b.txt": "b"})
+
+	_, err = repo.Edit(func(edit *Edit) (string, string, error) {
+		edit.WriteFile("c.txt", []byte("c"))
+		return "Add c", "", nil
+	})
+	if err != nil {
+		t.Fatal("Edit:", err)
+	}
+
+	if _, err := repo.Sync(ctx); err != nil {
+		t.Fatal("Sync:", err)
+	}
+	if got := readFile(t, repo.Repository, "b.txt"); got != "b" {
+		t.Errorf("b.txt = %q after Sync, want b", got)
+	}
+	if got := readFile(t, repo.Repository, "c.txt"); got != "c" {
+		t.Errorf("c.txt = %q after Sync, want c", got)
+	}
+
+	if err := repo.PushWithLease(ctx); err != nil {
+		t.Fatal("PushWithLease:", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := remote.Head(pool.BranchName("g/user")); got != head.Hash() {
+		t.Errorf("remote branch is at %v, want %v", got, head.Hash())
+	}
+
+	// A new workspace starts from the mirror, so it sees the new commit only
+	// once the mirror is updated.
+	if err := pool.UpdateMirror(ctx); err != nil {
+		t.Fatal("UpdateMirror:", err)
+	}
+
+	other, err := pool.Clone(ctx, false, "g/other")
+	if err != nil {
+		t.Fatal("Clone:", err)
+	}
+	if got := readFile(t, other.Repository, "b.txt"); got != "b" {
+		t.Errorf("b.txt = %q in a new workspace, want b", got)
+	}
+}
@@ -2,74 +2,249 @@ package gitwork
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
+	"github.com/go-git/go-git/v5"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/singleflight"
 )
 
-// Pool helps manage a pool of git repositories.
+// DefaultBranchPrefix is the default prefix of the branches that pooled
+// workspaces are checked out on.
+const DefaultBranchPrefix = "officer-data/"
+
+// Pool helps manage a pool of git repositories. The pool keeps metadata about
+// its workspaces in an index file in RootPath (see IndexFile), so that it
+// picks up the workspaces that it created before a restart. Only workspaces
+// made with an empty dstDir or a dstDir two levels deep, such as
+// <guild>/<user>, are picked up.
 type Pool struct {
 	Author    Author
 	RootPath  string
 	RemoteURL string
+	// BranchPrefix is prepended to the workspace path to form the name of the
+	// branch that the workspace is checked out on. Each workspace gets its
+	// own branch, so pushing one never affects another.
+	BranchPrefix string
+	// MergeDrivers maps file paths to the merge drivers used for them when
+	// syncing workspaces. Paths are relative to the repository root.
+	MergeDrivers map[string]MergeDriver
+	// MaxIdle is how long a workspace may go unused before Collect evicts
+	// it. Zero disables idle eviction.
+	MaxIdle time.Duration
+	// DiskBudget is the total size in bytes that the workspaces may take up.
+	// Collect evicts the least recently used workspaces until they fit. Zero
+	// means no budget.
+	DiskBudget int64
+	// Keep is called by Collect before evicting a workspace. If it returns
+	// true, then the workspace is kept. If Keep is nil, then workspaces with
+	// a pull request are kept. Workspaces with unpushed commits are always
+	// kept regardless.
+	Keep func(ctx context.Context, info WorkspaceInfo) (bool, error)
+	// Auth provides the credentials for cloning, fetching and pushing. If
+	// it's nil, then no credentials are used.
+	Auth Auth
+	// Mirror makes the pool keep a mirror of the remote in MirrorDir and
+	// create new workspaces from it. The workspaces share the mirror's
+	// objects, so creating one doesn't touch the remote. The mirror is only
+	// as fresh as the last UpdateMirror; see StartMirror. It's ignored if
+	// Store is set.
+	Mirror bool
+	// Store returns the store of the workspace at the given path, which is
+	// relative to RootPath. If it's nil, then workspaces are kept on the disk
+	// in RootPath. Workspaces that aren't on the disk, such as ones made with
+	// InMemory, are forgotten on restart. Store is called once per
+	// workspace, and the pool keeps the returned store until the workspace
+	// is evicted, so reopening the workspace finds the same repository.
+	Store func(path string) RepositoryStore
 
 	repoMu       sync.Mutex
 	repoFlight   singleflight.Group
 	repositories map[string]*PooledRepository
+	stores       map[string]RepositoryStore
+
+	indexMu    sync.Mutex
+	index      map[string]*WorkspaceInfo
+	indexDirty bool
+	indexTimer *time.Timer
+
+	mirrorMu sync.RWMutex
+	mirror   *git.Repository
 }
 
-// NewPool creates a new pool with the given store.
+// NewPool creates a new pool at the given root path, or at a gitwork
+// directory in the temporary directory if it's empty. Workspaces that already
+// exist in the root path are registered, but they're only opened once they're
+// used.
 func NewPool(rootPath, remoteURL string) (*Pool, error) {
 	if rootPath == "" {
-		rootPath = os.TempDir()
+		rootPath = filepath.Join(os.TempDir(), "gitwork")
 	}
 
 	if err := os.MkdirAll(rootPath, os.ModePerm); err != nil {
 		return nil, errors.Wrap(err, "failed to create pool root directory")
 	}
 
-	return &Pool{
-		Author:    DefaultAuthor,
-		RootPath:  rootPath,
-		RemoteURL: remoteURL,
-	}, nil
+	p := &Pool{
+		Author:       DefaultAuthor,
+		RootPath:     rootPath,
+		RemoteURL:    remoteURL,
+		BranchPrefix: DefaultBranchPrefix,
+		repositories: make(map[string]*PooledRepository),
+	}
+
+	if err := p.loadIndex(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
 }
 
 // Clone clones the repository at the given URL into the pool. If dstDir is
-// empty, then a random directory is created. If dstDir already exists, then
+// empty, then a random path is used. If dstDir already exists, then
 // the repository is opened instead.
+//
+// The returned repository is checked out on its own branch, which is named
+// after dstDir (see BranchName). The branch is created from the remote's
+// default branch if it doesn't exist yet.
+//
+// Workspaces that exist on disk, including ones from before a restart, are
+// reopened rather than cloned again. If the pool has a mirror, then new
+// workspaces are created from it and shallow is ignored.
 func (p *Pool) Clone(ctx context.Context, shallow bool, dstDir string) (*PooledRepository, error) {
+	return p.clone(ctx, shallow, dstDir, false)
+}
+
+// CloneAcquired is like Clone, but the repository is acquired before the pool
+// lets go of it, so that it can't be evicted before the caller gets to use
+// it. The caller must call Release once it's done.
+func (p *Pool) CloneAcquired(ctx context.Context, shallow bool, dstDir string) (*PooledRepository, error) {
+	return p.clone(ctx, shallow, dstDir, true)
+}
+
+func (p *Pool) clone(ctx context.Context, shallow bool, dstDir string, acquire bool) (*PooledRepository, error) {
 	if dstDir == "" {
 		var err error
-
-		dstDir, err = os.MkdirTemp(p.RootPath, "repo-")
+		dstDir, err = p.tempDir()
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to create temporary directory")
+			return nil, err
 		}
 	}
 
-	return p.lock(dstDir, func() (*Repository, error) {
-		return Clone(ctx, p.RemoteURL, shallow, AtDir(filepath.Join(p.RootPath, dstDir)))
+	dstDir = poolPath(dstDir)
+
+	return p.lock(dstDir, acquire, func() (*Repository, error) {
+		store := p.store(dstDir)
+
+		repo, err := Open(store)
+		if err != nil {
+			if p.Mirror && p.Store == nil {
+				repo, err = p.cloneFromMirror(ctx, filepath.Join(p.RootPath, dstDir))
+			} else {
+				repo, err = Clone(ctx, p.RemoteURL, shallow, p.Auth, store)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := repo.checkoutWorkspace(p.BranchName(dstDir)); err != nil {
+			return nil, err
+		}
+
+		return repo, nil
 	})
 }
 
 // Open opens a repository in the pool. The given path is relative to the pool's
-// root path.
+// root path. The repository is checked out on its own branch, similarly to
+// Clone.
 func (p *Pool) Open(dir string) (*PooledRepository, error) {
-	return p.lock(dir, func() (*Repository, error) {
-		return Open(AtDir(filepath.Join(p.RootPath, dir)))
+	dir = poolPath(dir)
+
+	return p.lock(dir, false, func() (*Repository, error) {
+		repo, err := Open(p.store(dir))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := repo.checkoutWorkspace(p.BranchName(dir)); err != nil {
+			return nil, err
+		}
+
+		return repo, nil
 	})
 }
 
-func (p *Pool) lock(path string, f func() (*Repository, error)) (*PooledRepository, error) {
+// tempDir returns a new random path for a workspace. The directory is created
+// if workspaces are kept on the disk.
+func (p *Pool) tempDir() (string, error) {
+	if p.Store != nil {
+		var b [8]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return "", errors.Wrap(err, "failed to generate workspace name")
+		}
+		return "repo-" + hex.EncodeToString(b[:]), nil
+	}
+
+	tmpDir, err := os.MkdirTemp(p.RootPath, "repo-")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temporary directory")
+	}
+
+	dir, err := filepath.Rel(p.RootPath, tmpDir)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to resolve temporary directory")
+	}
+
+	return dir, nil
+}
+
+// store returns the store of the workspace at the given path.
+func (p *Pool) store(path string) RepositoryStore {
+	if p.Store == nil {
+		return AtDir(filepath.Join(p.RootPath, filepath.FromSlash(path)))
+	}
+
+	p.repoMu.Lock()
+	defer p.repoMu.Unlock()
+
+	store, ok := p.stores[path]
+	if !ok {
+		store = p.Store(path)
+		if p.stores == nil {
+			p.stores = make(map[string]RepositoryStore)
+		}
+		p.stores[path] = store
+	}
+
+	return store
+}
+
+// BranchName returns the name of the branch that the workspace at the given
+// path is checked out on.
+func (p *Pool) BranchName(dir string) string {
+	return p.BranchPrefix + filepath.ToSlash(filepath.Clean(dir))
+}
+
+// lock returns the open repository at the given path, or opens it with f and
+// registers it. If acquire is true, then the repository is acquired while
+// it's still registered, so that Evict can't remove it in between.
+func (p *Pool) lock(path string, acquire bool, f func() (*Repository, error)) (*PooledRepository, error) {
 	p.repoMu.Lock()
 
 	repo, ok := p.repositories[path]
 	if ok {
+		if acquire {
+			repo.users++
+		}
 		p.repoMu.Unlock()
+		p.touch(repo)
 		return repo, nil
 	}
 
@@ -80,6 +255,12 @@ func (p *Pool) lock(path string, f func() (*Repository, error)) (*PooledReposito
 		if err != nil {
 			return nil, err
 		}
+		repo.Config.Author = p.Author
+		repo.Config.Auth = p.Auth
+		repo.Config.MergeDrivers = make(map[string]MergeDriver, len(p.MergeDrivers))
+		for path, driver := range p.MergeDrivers {
+			repo.Config.MergeDrivers[cleanPath(path)] = driver
+		}
 
 		pooled := &PooledRepository{
 			Repository: repo,
@@ -87,6 +268,9 @@ func (p *Pool) lock(path string, f func() (*Repository, error)) (*PooledReposito
 			path:       path,
 		}
 
+		p.markOwned(path)
+		p.touch(pooled)
+
 		p.repoMu.Lock()
 		p.repositories[path] = pooled
 		p.repoMu.Unlock()
@@ -97,12 +281,62 @@ func (p *Pool) lock(path string, f func() (*Repository, error)) (*PooledReposito
 		return nil, err
 	}
 
-	return v.(*PooledRepository), nil
+	pooled := v.(*PooledRepository)
+	if !acquire {
+		return pooled, nil
+	}
+
+	p.repoMu.Lock()
+	if p.repositories[path] != pooled {
+		// The repository was evicted before it could be acquired, so open
+		// it again.
+		p.repoMu.Unlock()
+		return p.lock(path, acquire, f)
+	}
+	pooled.users++
+	p.repoMu.Unlock()
+
+	return pooled, nil
+}
+
+// touch marks the workspace as just used. The upstream commit is recorded if
+// it's not known yet.
+func (p *Pool) touch(repo *PooledRepository) {
+	var upstream CommitHash
+	if info, ok := p.Info(repo.path); !ok || info.Upstream == "" {
+		upstream, _ = repo.upstreamHead()
+	}
+
+	p.updateInfo(repo.path, func(info *WorkspaceInfo) {
+		info.Branch = p.BranchName(repo.path)
+		info.LastUsedAt = time.Now()
+		if !upstream.IsZero() {
+			info.Upstream = upstream.String()
+		}
+	})
 }
 
 // Delete deletes all repositories that belong to the pool. Beware when calling
 // this method.
 func (p *Pool) Delete() error {
+	p.repoMu.Lock()
+	p.repositories = make(map[string]*PooledRepository)
+	p.stores = nil
+	p.repoMu.Unlock()
+
+	p.indexMu.Lock()
+	p.index = make(map[string]*WorkspaceInfo)
+	if p.indexTimer != nil {
+		p.indexTimer.Stop()
+		p.indexTimer = nil
+	}
+	p.indexDirty = false
+	p.indexMu.Unlock()
+
+	p.mirrorMu.Lock()
+	p.mirror = nil
+	p.mirrorMu.Unlock()
+
 	return os.RemoveAll(p.RootPath)
 }
 
@@ -111,6 +345,36 @@ type PooledRepository struct {
 	*Repository
 	pool *Pool
 	path string
+	// users is the number of Acquire calls without a matching Release. It's
+	// guarded by pool.repoMu.
+	users int
+}
+
+// Acquire marks the repository as in use until the matching Release, so that
+// the pool doesn't evict it in the meantime. Edits and syncs already hold the
+// repository while they run; Acquire is for holding it across several calls,
+// such as between previewing a change and committing it.
+func (r *PooledRepository) Acquire() {
+	r.pool.repoMu.Lock()
+	r.users++
+	r.pool.repoMu.Unlock()
+}
+
+// Release undoes an Acquire.
+func (r *PooledRepository) Release() {
+	r.pool.repoMu.Lock()
+	defer r.pool.repoMu.Unlock()
+
+	if r.users == 0 {
+		panic("gitwork: Release without Acquire")
+	}
+	r.users--
+}
+
+func (r *PooledRepository) inUse() bool {
+	r.pool.repoMu.Lock()
+	defer r.pool.repoMu.Unlock()
+	return r.users > 0
 }
 
 // Path returns the path to the repository in the pool.
@@ -127,3 +391,52 @@ func (r *PooledRepository) AbsPath() string {
 func (r *PooledRepository) Pool() *Pool {
 	return r.pool
 }
+
+// Info returns the metadata that the pool keeps about the repository.
+func (r *PooledRepository) Info() WorkspaceInfo {
+	info, _ := r.pool.Info(r.path)
+	return info
+}
+
+// SetPullRequest records the number of the pull request made from the
+// repository's branch in the pool's index. Zero means there's none.
+func (r *PooledRepository) SetPullRequest(number int) {
+	r.pool.updateInfo(r.path, func(info *WorkspaceInfo) {
+		info.PullRequest = number
+	})
+}
+
+// SetOwner records who the repository belongs to in the pool's index.
+func (r *PooledRepository) SetOwner(owner string) {
+	r.pool.updateInfo(r.path, func(info *WorkspaceInfo) {
+		info.Owner = owner
+	})
+}
+
+// Fetch is Repository.Fetch. The new upstream commit is recorded in the pool's
+// index.
+func (r *PooledRepository) Fetch(ctx context.Context) (CommitHash, error) {
+	upstream, err := r.Repository.Fetch(ctx)
+	if err != nil {
+		return upstream, err
+	}
+	r.setUpstream(upstream)
+	return upstream, nil
+}
+
+// Sync is Repository.Sync. The new upstream commit is recorded in the pool's
+// index.
+func (r *PooledRepository) Sync(ctx context.Context) (*SyncResult, error) {
+	result, err := r.Repository.Sync(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.setUpstream(result.Upstream)
+	return result, nil
+}
+
+func (r *PooledRepository) setUpstream(upstream CommitHash) {
+	r.pool.updateInfo(r.path, func(info *WorkspaceInfo) {
+		info.Upstream = upstream.String()
+	})
+}
@@ -0,0 +1,170 @@
+package gitwork
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newInMemoryPool(t *testing.T, remote *testRemote) *Pool {
+	t.Helper()
+
+	pool := newTestPool(t, t.TempDir(), remote)
+	pool.Store = func(string) RepositoryStore { return InMemory() }
+	return pool
+}
+
+func TestPoolInMemory(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+	pool := newInMemoryPool(t, remote)
+
+	repo, err := pool.Clone(ctx, false, "g/user")
+	if err != nil {
+		t.Fatal("Clone:", err)
+	}
+	if got := readFile(t, repo.Repository, "a.txt"); got != "a" {
+		t.Errorf("a.txt = %q, want a", got)
+	}
+
+	again, err := pool.Clone(ctx, false, "g/user")
+	if err != nil {
+		t.Fatal("Clone again:", err)
+	}
+	if again != repo {
+		t.Error("Clone of the same path returned a different repository")
+	}
+
+	_, err = repo.Edit(func(edit *Edit) (string, string, error) {
+		edit.WriteFile("a.txt", []byte("ours"))
+		return "Update a", "", nil
+	})
+	if err != nil {
+		t.Fatal("Edit:", err)
+	}
+
+	upstream := remote.Push("Add b", map[string]string{"b.txt": "b"})
+
+	result, err := repo.Sync(ctx)
+	if err != nil {
+		t.Fatal("Sync:", err)
+	}
+	if result.Upstream != upstream {
+		t.Errorf("synced with %v, want %v", result.Upstream, upstream)
+	}
+	if info := repo.Info(); info.Upstream != upstream.String() {
+		t.Errorf("index has upstream %q, want %v", info.Upstream, upstream)
+	}
+	if got := readFile(t, repo.Repository, "a.txt"); got != "ours" {
+		t.Errorf("a.txt = %q after Sync, want ours", got)
+	}
+	if got := readFile(t, repo.Repository, "b.txt"); got != "b" {
+		t.Errorf("b.txt = %q after Sync, want b", got)
+	}
+
+	if err := repo.PushWithLease(ctx); err != nil {
+		t.Fatal("PushWithLease:", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := remote.Head(pool.BranchName("g/user")); got != head.Hash() {
+		t.Errorf("remote branch is at %v, want %v", got, head.Hash())
+	}
+
+	if _, err := os.Stat(filepath.Join(pool.RootPath, "g")); !os.IsNotExist(err) {
+		t.Errorf("in-memory workspace touched the disk: %v", err)
+	}
+}
+
+func TestPoolInMemoryTempDir(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+	pool := newInMemoryPool(t, remote)
+
+	repo, err := pool.Clone(ctx, false, "")
+	if err != nil {
+		t.Fatal("Clone:", err)
+	}
+	if got := readFile(t, repo.Repository, "a.txt"); got != "a" {
+		t.Errorf("a.txt = %q, want a", got)
+	}
+	if _, err := os.Stat(repo.AbsPath()); !os.IsNotExist(err) {
+		t.Errorf("in-memory workspace touched the disk: %v", err)
+	}
+
+	if err := pool.Evict(repo.Path()); err != nil {
+		t.Fatal("Evict:", err)
+	}
+	if _, ok := pool.Info(repo.Path()); ok {
+		t.Error("evicted workspace is still in the index")
+	}
+}
+
+// TestPoolInMemoryReopen checks that the pool keeps using the store that it
+// cloned a workspace into once it has to open the workspace again.
+func TestPoolInMemoryReopen(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+	pool := newInMemoryPool(t, remote)
+	pool.MaxIdle = time.Nanosecond
+
+	var stores []string
+	pool.Store = func(path string) RepositoryStore {
+		stores = append(stores, path)
+		return InMemory()
+	}
+
+	repo, err := pool.Clone(ctx, false, "g/user")
+	if err != nil {
+		t.Fatal("Clone:", err)
+	}
+	_, err = repo.Edit(func(edit *Edit) (string, string, error) {
+		edit.WriteFile("a.txt", []byte("ours"))
+		return "Update a", "", nil
+	})
+	if err != nil {
+		t.Fatal("Edit:", err)
+	}
+
+	if _, err := pool.Clone(ctx, false, "g/clean"); err != nil {
+		t.Fatal("Clone:", err)
+	}
+
+	// Forget the open repositories, as a restart would, so that Collect and
+	// Open have to open them again.
+	pool.repoMu.Lock()
+	pool.repositories = make(map[string]*PooledRepository)
+	pool.repoMu.Unlock()
+
+	evicted, err := pool.Collect(ctx)
+	if err != nil {
+		t.Fatal("Collect:", err)
+	}
+	if !equalStrings(evicted, []string{"g/clean"}) {
+		t.Errorf("evicted %q, want only g/clean", evicted)
+	}
+
+	reopened, err := pool.Open("g/user")
+	if err != nil {
+		t.Fatal("Open:", err)
+	}
+	if got := readFile(t, reopened.Repository, "a.txt"); got != "ours" {
+		t.Errorf("a.txt = %q after reopening, want ours", got)
+	}
+
+	if !equalStrings(stores, []string{"g/user", "g/clean"}) {
+		t.Errorf("Store was called for %q, want once for each workspace", stores)
+	}
+
+	// The evicted workspace's store is gone, so cloning it again starts over.
+	if _, err := pool.Clone(ctx, false, "g/clean"); err != nil {
+		t.Fatal("Clone after Evict:", err)
+	}
+	if !equalStrings(stores, []string{"g/user", "g/clean", "g/clean"}) {
+		t.Errorf("Store was called for %q, want a new store for g/clean", stores)
+	}
+}
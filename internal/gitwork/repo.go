@@ -5,14 +5,20 @@ import (
 	"go/doc"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/cache"
 	"github.com/go-git/go-git/v5/storage"
 	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/pkg/errors"
 
 	gitconfig "github.com/go-git/go-git/v5/config"
@@ -20,13 +26,18 @@ import (
 	gitobject "github.com/go-git/go-git/v5/plumbing/object"
 )
 
-type RepositoryStore func() (billy.Filesystem, error)
+// RepositoryStore returns the storage of a repository's .git directory and the
+// filesystem of its worktree. A store must return the same repository every
+// time it's called, so that a repository created with it can be opened with
+// it again.
+type RepositoryStore func() (storage.Storer, billy.Filesystem, error)
 
 // AtDir returns a RepositoryStore that creates a repository at the given
 // directory.
 func AtDir(path string) RepositoryStore {
-	return func() (billy.Filesystem, error) {
-		return osfs.New(path), nil
+	return func() (storage.Storer, billy.Filesystem, error) {
+		fs := osfs.New(path)
+		return newStorage(fs), fs, nil
 	}
 }
 
@@ -34,17 +45,40 @@ func AtDir(path string) RepositoryStore {
 // directory.
 func AtTempDir(dir string) RepositoryStore {
 	dir = filepath.Join(os.TempDir(), dir)
-	return func() (billy.Filesystem, error) {
-		tmp, err := os.MkdirTemp(dir, "gitwork")
+
+	var once sync.Once
+	var tmp string
+	var err error
+
+	return func() (storage.Storer, billy.Filesystem, error) {
+		once.Do(func() { tmp, err = os.MkdirTemp(dir, "gitwork") })
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		return AtDir(tmp)()
 	}
 }
 
+// InMemory returns a RepositoryStore that keeps the repository in memory. The
+// repository is gone once the store is no longer referenced, which is useful
+// for tests and for edits that shouldn't be kept.
+func InMemory() RepositoryStore {
+	storer := memory.NewStorage()
+	fs := memfs.New()
+
+	return func() (storage.Storer, billy.Filesystem, error) {
+		return storer, fs, nil
+	}
+}
+
 func newStorage(fs billy.Filesystem) storage.Storer {
-	return filesystem.NewStorageWithOptions(fs, nil, filesystem.Options{
+	dotgit, err := fs.Chroot(git.GitDirName)
+	if err != nil {
+		// Chroot never fails for the filesystems that we use.
+		panic(err)
+	}
+
+	return filesystem.NewStorageWithOptions(dotgit, cache.NewObjectLRUDefault(), filesystem.Options{
 		ExclusiveAccess:    true,
 		MaxOpenDescriptors: 4,
 	})
@@ -77,37 +111,57 @@ type Repository struct {
 	*git.Repository
 	Config struct {
 		Author Author
+		// MergeDrivers maps file paths to the merge drivers used for them
+		// when syncing. Paths are relative to the repository root.
+		MergeDrivers map[string]MergeDriver
+		// Auth provides the credentials for fetching and pushing. If it's
+		// nil, then no credentials are used.
+		Auth Auth
 	}
+
+	// mu serializes everything that changes the worktree, the index or the
+	// refs, so that, for example, a Sync can't reset the files that an Edit
+	// is about to commit. Exported methods that change them hold it, and
+	// their unexported counterparts expect it to be held.
+	mu sync.Mutex
 }
 
-// Clone clones a repository into the workspace.
-func Clone(ctx context.Context, url string, shallow bool, dst RepositoryStore) (*Repository, error) {
-	fs, err := dst()
+// Clone clones a repository into the workspace. auth provides the credentials
+// for the clone and is kept for later fetches and pushes; it may be nil.
+func Clone(ctx context.Context, url string, shallow bool, auth Auth, dst RepositoryStore) (*Repository, error) {
+	storer, fs, err := dst()
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := authMethod(ctx, auth)
 	if err != nil {
 		return nil, err
 	}
 
-	opts := &git.CloneOptions{URL: url}
+	opts := &git.CloneOptions{URL: url, Auth: method}
 	if shallow {
 		opts.Depth = 1
 	}
 
-	repo, err := git.CloneContext(ctx, newStorage(fs), fs, opts)
+	repo, err := git.CloneContext(ctx, storer, fs, opts)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot clone repository")
 	}
 
-	return newRepository(repo), nil
+	r := newRepository(repo)
+	r.Config.Auth = auth
+	return r, nil
 }
 
 // Open opens an existing repository.
 func Open(dst RepositoryStore) (*Repository, error) {
-	fs, err := dst()
+	storer, fs, err := dst()
 	if err != nil {
 		return nil, err
 	}
 
-	repo, err := git.Open(newStorage(fs), fs)
+	repo, err := git.Open(storer, fs)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot open repository")
 	}
@@ -117,12 +171,12 @@ func Open(dst RepositoryStore) (*Repository, error) {
 
 // Init initializes the workspace with nothing.
 func Init(dst RepositoryStore) (*Repository, error) {
-	fs, err := dst()
+	storer, fs, err := dst()
 	if err != nil {
 		return nil, err
 	}
 
-	repo, err := git.Init(newStorage(fs), fs)
+	repo, err := git.Init(storer, fs)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot init repository")
 	}
@@ -154,11 +208,13 @@ func (r *Repository) FS() billy.Filesystem {
 }
 
 // EditFile edits or creates a file in the repository. f is called on the file
-// once it's opened. The file is locked using flock while f is running.
+// once it's opened. The file is locked using flock while f is running. New
+// files are created like os.Create does, so git doesn't see them as
+// executable.
 func (r *Repository) OpenFile(path string, flag int) (*RepositoryFile, error) {
 	fs := r.FS()
 
-	file, err := fs.OpenFile(path, flag, os.ModePerm)
+	file, err := fs.OpenFile(path, flag, 0666)
 	if err != nil {
 		return nil, err
 	}
@@ -177,20 +233,63 @@ func (r *Repository) OpenFile(path string, flag int) (*RepositoryFile, error) {
 	return repoFile, nil
 }
 
-// Add adds the given files to the index.
+// ReadFile reads the whole file at the given path.
+func (r *Repository) ReadFile(path string) ([]byte, error) {
+	return util.ReadFile(r.FS(), path)
+}
+
+// ReadFileAt reads the whole file at the given path as of the given commit. If
+// the file does not exist, then os.ErrNotExist is returned.
+func (r *Repository) ReadFileAt(hash CommitHash, path string) ([]byte, error) {
+	commit, err := r.CommitObject(hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get commit")
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get commit tree")
+	}
+
+	data, err := treeFile(tree, cleanPath(path))
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, os.ErrNotExist
+	}
+
+	return data, nil
+}
+
+// Add adds the given files to the index. Prefer Edit, which stages the files
+// that it changes.
 func (r *Repository) Add(path string) error {
-	_, err := r.Worktree().Add(path)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.Worktree().Add(cleanPath(path))
 	return err
 }
 
 // AddAll adds all files to the index.
 func (r *Repository) AddAll() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	return r.Worktree().AddWithOptions(&git.AddOptions{All: true})
 }
 
 // Commit commits the changes in the repository. Changes must be added using
-// Add.
+// Add; use Edit to change, stage and commit files in one go.
 func (r *Repository) Commit(title, body string) (CommitHash, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.commit(title, body)
+}
+
+func (r *Repository) commit(title, body string) (CommitHash, error) {
 	message := title
 	if body != "" {
 		message += "\n\n" + columnWrap(body, 72)
@@ -214,26 +313,178 @@ func columnWrap(txt string, col int) string {
 	return buf.String()
 }
 
-// Checkout checks out to the given ref.
-func (r *Repository) Checkout(ref string, create bool) error {
+// Checkout checks out to the given branch. If create is true, then the branch
+// is created from the current HEAD.
+func (r *Repository) Checkout(branch string, create bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.checkout(branch, create)
+}
+
+func (r *Repository) checkout(branch string, create bool) error {
 	return r.Worktree().Checkout(&git.CheckoutOptions{
-		Branch: gitplumbing.ReferenceName(ref),
+		Branch: gitplumbing.NewBranchReferenceName(branch),
 		Create: create,
 		Keep:   true,
 	})
 }
 
-// Push pushes the current branch to the remote.
+// checkoutWorkspace ensures that the repository is checked out on the given
+// branch. If the branch doesn't exist, then it is created from the current
+// HEAD, which is assumed to be the upstream branch, and set to track it.
+func (r *Repository) checkoutWorkspace(branch string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	head, err := r.Head()
+	if err != nil {
+		return errors.Wrap(err, "cannot get HEAD")
+	}
+
+	if head.Name() == gitplumbing.NewBranchReferenceName(branch) {
+		return nil
+	}
+
+	_, err = r.Reference(gitplumbing.NewBranchReferenceName(branch), false)
+	if err == nil {
+		if err := r.checkout(branch, false); err != nil {
+			return errors.Wrapf(err, "cannot checkout branch %q", branch)
+		}
+		return nil
+	}
+
+	if !head.Name().IsBranch() {
+		return errors.New("HEAD is not on the upstream branch")
+	}
+
+	if err := r.checkout(branch, true); err != nil {
+		return errors.Wrapf(err, "cannot create branch %q", branch)
+	}
+
+	err = r.CreateBranch(&gitconfig.Branch{
+		Name:   branch,
+		Remote: "origin",
+		Merge:  head.Name(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "cannot track upstream for branch %q", branch)
+	}
+
+	return nil
+}
+
+// UpstreamBranch returns the name of the remote branch that the current branch
+// tracks, such as "main".
+func (r *Repository) UpstreamBranch() (string, error) {
+	head, err := r.Head()
+	if err != nil {
+		return "", errors.Wrap(err, "cannot get HEAD")
+	}
+
+	cfg, err := r.Repository.Config()
+	if err != nil {
+		return "", errors.Wrap(err, "cannot get config")
+	}
+
+	branch, ok := cfg.Branches[head.Name().Short()]
+	if !ok || branch.Merge == "" {
+		return "", errors.Errorf("branch %q has no upstream", head.Name().Short())
+	}
+
+	return branch.Merge.Short(), nil
+}
+
+// upstreamHead returns the commit of the remote branch that the current
+// branch tracks, as of the last fetch.
+func (r *Repository) upstreamHead() (CommitHash, error) {
+	upstream, err := r.UpstreamBranch()
+	if err != nil {
+		return CommitHash{}, err
+	}
+
+	ref, err := r.Reference(gitplumbing.NewRemoteReferenceName("origin", upstream), true)
+	if err != nil {
+		return CommitHash{}, errors.Wrapf(err, "cannot find remote branch %q", upstream)
+	}
+
+	return ref.Hash(), nil
+}
+
+// UnpushedCommits returns the commits on the current branch that are neither
+// upstream nor pushed to the remote branch of the same name, newest first.
+func (r *Repository) UnpushedCommits() ([]*gitobject.Commit, error) {
+	upstream, err := r.UpstreamBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := r.CommitsAhead(upstream)
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get HEAD")
+	}
+
+	pushedRef, err := r.Reference(gitplumbing.NewRemoteReferenceName("origin", head.Name().Short()), true)
+	if err != nil {
+		if err == gitplumbing.ErrReferenceNotFound {
+			return pending, nil
+		}
+		return nil, errors.Wrap(err, "cannot find pushed branch")
+	}
+
+	pushed, err := r.CommitObject(pushedRef.Hash())
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get pushed commit")
+	}
+
+	for i, commit := range pending {
+		if commit.Hash == pushed.Hash {
+			return pending[:i], nil
+		}
+
+		isPushed, err := commit.IsAncestor(pushed)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot check pushed commit")
+		}
+		if isPushed {
+			return pending[:i], nil
+		}
+	}
+
+	return pending, nil
+}
+
+// Push pushes the current branch to a branch of the same name on the remote.
+// If force is true, then the remote branch is overwritten even if it has
+// diverged.
 func (r *Repository) Push(ctx context.Context, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	head, err := r.Head()
 	if err != nil {
 		return errors.Wrap(err, "cannot get HEAD")
 	}
 
+	if !head.Name().IsBranch() {
+		return errors.New("HEAD is not on a branch")
+	}
+
+	method, err := authMethod(ctx, r.Config.Auth)
+	if err != nil {
+		return err
+	}
+
 	err = r.Repository.PushContext(ctx, &git.PushOptions{
 		RemoteName: "origin",
+		Auth:       method,
 		RefSpecs: []gitconfig.RefSpec{
-			refSpecForBranch(head.Name().Short()),
+			refSpecForBranch(head.Name().Short(), force),
 		},
 	})
 
@@ -247,9 +498,207 @@ func (r *Repository) Push(ctx context.Context, force bool) error {
 	return nil
 }
 
-func refSpecForBranch(branch string) gitconfig.RefSpec {
-	return gitconfig.RefSpec("+" +
-		gitplumbing.NewBranchReferenceName(branch).String() + ":" +
-		gitplumbing.NewRemoteReferenceName("origin", branch).String(),
-	)
+// ErrStaleLease is returned by PushWithLease when the remote branch has moved
+// since it was last fetched or pushed.
+var ErrStaleLease = errors.New("remote branch has changed since it was last pushed")
+
+// PushWithLease pushes the current branch like Push, overwriting the remote
+// branch even if it has diverged, but only if the remote branch is still
+// where the repository last saw it, similarly to git push --force-with-lease.
+// Otherwise, ErrStaleLease is returned and nothing is pushed. If the
+// repository has never seen the remote branch, then it's only created if it
+// doesn't exist yet.
+func (r *Repository) PushWithLease(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	head, err := r.Head()
+	if err != nil {
+		return errors.Wrap(err, "cannot get HEAD")
+	}
+
+	if !head.Name().IsBranch() {
+		return errors.New("HEAD is not on a branch")
+	}
+
+	branch := head.Name().Short()
+
+	method, err := authMethod(ctx, r.Config.Auth)
+	if err != nil {
+		return err
+	}
+
+	remote, err := r.Remote("origin")
+	if err != nil {
+		return errors.Wrap(err, "cannot get origin remote")
+	}
+
+	remoteRefs, err := remote.ListContext(ctx, &git.ListOptions{Auth: method})
+	if err != nil {
+		return errors.Wrap(err, "cannot list remote branches")
+	}
+
+	var remoteHash CommitHash
+	for _, ref := range remoteRefs {
+		if ref.Name() == head.Name() {
+			remoteHash = ref.Hash()
+			break
+		}
+	}
+
+	var leaseHash CommitHash
+	lease, err := r.Reference(gitplumbing.NewRemoteReferenceName("origin", branch), true)
+	if err == nil {
+		leaseHash = lease.Hash()
+	}
+
+	if remoteHash != leaseHash {
+		return ErrStaleLease
+	}
+
+	opts := &git.PushOptions{
+		RemoteName: "origin",
+		Auth:       method,
+		RefSpecs: []gitconfig.RefSpec{
+			refSpecForBranch(branch, !leaseHash.IsZero()),
+		},
+	}
+	if !leaseHash.IsZero() {
+		// Guard against the branch moving between listing and pushing.
+		opts.RequireRemoteRefs = []gitconfig.RefSpec{
+			gitconfig.RefSpec(leaseHash.String() + ":" + head.Name().String()),
+		}
+	}
+
+	err = r.Repository.PushContext(ctx, opts)
+	if err != nil {
+		if err == git.NoErrAlreadyUpToDate {
+			return nil
+		}
+		return errors.Wrap(err, "cannot push")
+	}
+
+	return nil
+}
+
+func refSpecForBranch(branch string, force bool) gitconfig.RefSpec {
+	ref := gitplumbing.NewBranchReferenceName(branch).String()
+
+	spec := ref + ":" + ref
+	if force {
+		spec = "+" + spec
+	}
+
+	return gitconfig.RefSpec(spec)
+}
+
+// ChangedFiles returns the paths of the files that differ between HEAD and the
+// given branch of the origin remote, sorted. Unlike CommitsAhead, it only
+// looks at the trees, so commits that change nothing in the end don't count.
+func (r *Repository) ChangedFiles(remoteBranch string) ([]string, error) {
+	head, err := r.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get HEAD")
+	}
+
+	remoteRef, err := r.Reference(gitplumbing.NewRemoteReferenceName("origin", remoteBranch), true)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot find remote branch %q", remoteBranch)
+	}
+
+	headTree, err := r.commitTree(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	remoteTree, err := r.commitTree(remoteRef.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := remoteTree.Diff(headTree)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot diff trees")
+	}
+
+	paths := make(map[string]struct{}, len(changes))
+	for _, change := range changes {
+		if change.From.Name != "" {
+			paths[change.From.Name] = struct{}{}
+		}
+		if change.To.Name != "" {
+			paths[change.To.Name] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	return sorted, nil
+}
+
+func (r *Repository) commitTree(hash CommitHash) (*gitobject.Tree, error) {
+	commit, err := r.CommitObject(hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get commit %s", hash)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get tree of %s", hash)
+	}
+
+	return tree, nil
+}
+
+// CommitsAhead returns the commits that are on HEAD but not on the given
+// branch of the origin remote, newest first. History is assumed to be mostly
+// linear, which is the case for commits made by gitwork.
+func (r *Repository) CommitsAhead(remoteBranch string) ([]*gitobject.Commit, error) {
+	head, err := r.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get HEAD")
+	}
+
+	remoteRef, err := r.Reference(gitplumbing.NewRemoteReferenceName("origin", remoteBranch), true)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot find remote branch %q", remoteBranch)
+	}
+
+	upstream, err := r.CommitObject(remoteRef.Hash())
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get upstream commit")
+	}
+
+	var commits []*gitobject.Commit
+
+	commit, err := r.CommitObject(head.Hash())
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get HEAD commit")
+	}
+
+	for commit.Hash != upstream.Hash {
+		isAncestor, err := commit.IsAncestor(upstream)
+		if err != nil || isAncestor {
+			// Errors here are usually caused by shallow clones missing older
+			// commits, so we just stop.
+			break
+		}
+
+		commits = append(commits, commit)
+
+		if commit.NumParents() == 0 {
+			break
+		}
+
+		commit, err = commit.Parent(0)
+		if err != nil {
+			break
+		}
+	}
+
+	return commits, nil
 }
@@ -0,0 +1,157 @@
+package gitwork
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+
+	gitconfig "github.com/go-git/go-git/v5/config"
+)
+
+func commitFile(t *testing.T, repo *Repository, path, content string) CommitHash {
+	t.Helper()
+
+	if err := util.WriteFile(repo.FS(), path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Add(path); err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := repo.Commit("Write "+path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestPushWithLease(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+
+	repo, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.checkoutWorkspace("pr"); err != nil {
+		t.Fatal(err)
+	}
+
+	first := commitFile(t, repo, "b.txt", "b")
+	if err := repo.PushWithLease(ctx); err != nil {
+		t.Fatal("first push:", err)
+	}
+	if got := remote.Head("pr"); got != first {
+		t.Fatalf("remote pr = %s, want %s", got, first)
+	}
+
+	// Amending the pushed commit needs a force push, which the lease allows
+	// since nobody else touched the branch.
+	if err := repo.resetHard(first); err != nil {
+		t.Fatal(err)
+	}
+	second := commitFile(t, repo, "b.txt", "b2")
+	if err := repo.PushWithLease(ctx); err != nil {
+		t.Fatal("second push:", err)
+	}
+	if got := remote.Head("pr"); got != second {
+		t.Fatalf("remote pr = %s, want %s", got, second)
+	}
+
+	// Someone else pushes to the branch.
+	other, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs := commitFile(t, other, "c.txt", "c")
+	err = other.Repository.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{"+refs/heads/master:refs/heads/pr"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commitFile(t, repo, "b.txt", "b3")
+	if err := repo.PushWithLease(ctx); !errors.Is(err, ErrStaleLease) {
+		t.Fatalf("push over someone else's commit: error = %v, want ErrStaleLease", err)
+	}
+	if got := remote.Head("pr"); got != theirs {
+		t.Fatalf("remote pr = %s, want their commit %s", got, theirs)
+	}
+}
+
+func TestPushWithLeaseNewBranch(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+
+	repo, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.checkoutWorkspace("pr"); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, repo, "b.txt", "b")
+
+	// The branch appears on the remote before the workspace has seen it.
+	other, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = other.Repository.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []gitconfig.RefSpec{"refs/heads/master:refs/heads/pr"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.PushWithLease(ctx); !errors.Is(err, ErrStaleLease) {
+		t.Fatalf("error = %v, want ErrStaleLease", err)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+
+	repo, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.checkoutWorkspace("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	commitFile(t, repo, "a.txt", "ours")
+	commitFile(t, repo, "b.txt", "b")
+
+	changed, err := repo.ChangedFiles("master")
+	if err != nil {
+		t.Fatal("ChangedFiles:", err)
+	}
+	if !equalStrings(changed, []string{"a.txt", "b.txt"}) {
+		t.Errorf("ChangedFiles = %q, want a.txt and b.txt", changed)
+	}
+
+	// Commits that undo each other change nothing.
+	commitFile(t, repo, "a.txt", "a")
+	if err := repo.Worktree().RemoveGlob("b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("Remove b.txt", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err = repo.ChangedFiles("master")
+	if err != nil {
+		t.Fatal("ChangedFiles:", err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("ChangedFiles = %q after reverting everything, want none", changed)
+	}
+}
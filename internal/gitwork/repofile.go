@@ -1,6 +1,7 @@
 package gitwork
 
 import (
+	"io"
 	"sync/atomic"
 
 	"github.com/go-git/go-billy/v5"
@@ -32,7 +33,12 @@ func (r *RepositoryFile) Close() error {
 	return lockedErr
 }
 
-// Wipe wipes the content of the file.
+// Wipe wipes the content of the file and rewinds to its start, so that the
+// next write starts at the beginning of the file.
 func (r *RepositoryFile) Wipe() error {
-	return r.File.Truncate(0)
+	if err := r.File.Truncate(0); err != nil {
+		return err
+	}
+	_, err := r.File.Seek(0, io.SeekStart)
+	return err
 }
@@ -0,0 +1,478 @@
+package gitwork
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/pkg/errors"
+
+	gitconfig "github.com/go-git/go-git/v5/config"
+	gitplumbing "github.com/go-git/go-git/v5/plumbing"
+	gitobject "github.com/go-git/go-git/v5/plumbing/object"
+	gittransport "github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// ErrConflict is returned when a file cannot be merged automatically.
+var ErrConflict = errors.New("conflicting changes")
+
+// ErrShallow is returned when syncing a shallow clone. go-git cannot negotiate
+// a fetch of new commits into a shallow clone, since it walks the history past
+// the shallow boundary.
+var ErrShallow = errors.New("shallow clones cannot be synced, clone the full history instead")
+
+// ConflictError is returned when a file cannot be merged automatically. It
+// matches ErrConflict using errors.Is.
+type ConflictError struct {
+	Path string
+	// Err is the error returned by the merge driver, if any.
+	Err error
+}
+
+// Error implements error.
+func (e *ConflictError) Error() string {
+	if e.Err != nil {
+		return "conflict in " + e.Path + ": " + e.Err.Error()
+	}
+	return "conflict in " + e.Path
+}
+
+// Is returns true if target is ErrConflict.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Unwrap returns the merge driver's error.
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// MergeDriver does a three-way merge of a file's contents. A nil slice means
+// that the file does not exist in that version. A nil slice may be returned
+// to delete the file. Any error returned is treated as a conflict.
+type MergeDriver func(base, ours, theirs []byte) ([]byte, error)
+
+// DroppedCommit is a commit that could not be replayed.
+type DroppedCommit struct {
+	*gitobject.Commit
+	// Err is the reason why the commit was dropped. It is usually a
+	// *ConflictError.
+	Err error
+}
+
+// SyncResult is the result of a Sync.
+type SyncResult struct {
+	// Upstream is the upstream commit that the branch is now based on.
+	Upstream CommitHash
+	// Replayed is the list of commits that were replayed onto the new
+	// upstream, oldest first. The commits are the new ones.
+	Replayed []*gitobject.Commit
+	// Dropped is the list of commits that could not be replayed onto the new
+	// upstream because they conflict with it, oldest first.
+	Dropped []DroppedCommit
+	// Upstreamed is the list of commits whose changes the new upstream
+	// already has, such as ones that were squash-merged, oldest first. They
+	// are not replayed, since they would be empty.
+	Upstreamed []*gitobject.Commit
+	// Discarded is the list of files whose uncommitted changes were thrown
+	// away to move the branch onto the new upstream.
+	Discarded []string
+}
+
+// Fetch fetches the upstream branch of the current branch from the origin
+// remote. It returns the new upstream commit.
+func (r *Repository) Fetch(ctx context.Context) (CommitHash, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.fetch(ctx)
+}
+
+func (r *Repository) fetch(ctx context.Context) (CommitHash, error) {
+	upstream, err := r.UpstreamBranch()
+	if err != nil {
+		return CommitHash{}, err
+	}
+
+	remoteRef := gitplumbing.NewRemoteReferenceName("origin", upstream)
+
+	method, err := authMethod(ctx, r.Config.Auth)
+	if err != nil {
+		return CommitHash{}, err
+	}
+
+	err = r.Repository.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: "origin",
+		Auth:       method,
+		RefSpecs: []gitconfig.RefSpec{gitconfig.RefSpec(
+			"+" + gitplumbing.NewBranchReferenceName(upstream).String() + ":" + remoteRef.String(),
+		)},
+	})
+	// Shallow clones report an up-to-date fetch as an empty request.
+	if err != nil && err != git.NoErrAlreadyUpToDate && err != gittransport.ErrEmptyUploadPackRequest {
+		return CommitHash{}, errors.Wrap(err, "cannot fetch")
+	}
+
+	ref, err := r.Reference(remoteRef, true)
+	if err != nil {
+		return CommitHash{}, errors.Wrapf(err, "cannot find remote branch %q", upstream)
+	}
+
+	return ref.Hash(), nil
+}
+
+// Sync fetches the upstream branch and replays the commits of the current
+// branch onto it, similarly to git pull --rebase. Commits that cannot be
+// replayed cleanly are dropped and reported in the result. Uncommitted changes
+// are discarded if the branch has to move, and are also reported.
+//
+// Sync is safe to call concurrently with Edit, Push and the other methods
+// that change the worktree.
+func (r *Repository) Sync(ctx context.Context) (*SyncResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	shallows, err := r.Storer.Shallow()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read shallow commits")
+	}
+	if len(shallows) > 0 {
+		return nil, ErrShallow
+	}
+
+	upstream, err := r.UpstreamBranch()
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := r.CommitsAhead(upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get HEAD")
+	}
+
+	newBase, err := r.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{Upstream: newBase}
+
+	isBased, err := r.isAncestor(newBase, head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if isBased {
+		// Nothing new upstream.
+		return result, nil
+	}
+
+	result.Discarded, err = r.uncommittedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.resetHard(newBase); err != nil {
+		return nil, err
+	}
+
+	// Replay the oldest commit first.
+	for i := len(pending) - 1; i >= 0; i-- {
+		commit, err := r.replay(pending[i])
+		if err != nil {
+			if errors.Is(err, ErrConflict) {
+				result.Dropped = append(result.Dropped, DroppedCommit{pending[i], err})
+				continue
+			}
+
+			// Put the branch back to where it was so we don't lose anything.
+			if resetErr := r.resetHard(head.Hash()); resetErr != nil {
+				return nil, errors.Wrap(resetErr, "cannot restore branch after failed sync")
+			}
+
+			return nil, errors.Wrapf(err, "cannot replay commit %s", pending[i].Hash)
+		}
+
+		if commit == nil {
+			result.Upstreamed = append(result.Upstreamed, pending[i])
+			continue
+		}
+
+		result.Replayed = append(result.Replayed, commit)
+	}
+
+	return result, nil
+}
+
+// uncommittedFiles returns the tracked files with changes that aren't
+// committed, sorted by path.
+func (r *Repository) uncommittedFiles() ([]string, error) {
+	status, err := r.Worktree().Status()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get worktree status")
+	}
+
+	var paths []string
+	for path, file := range status {
+		if file.Worktree == git.Untracked && file.Staging == git.Untracked {
+			continue
+		}
+		if file.Worktree != git.Unmodified || file.Staging != git.Unmodified {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func (r *Repository) isAncestor(ancestor, of CommitHash) (bool, error) {
+	if ancestor == of {
+		return true, nil
+	}
+
+	a, err := r.CommitObject(ancestor)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot get commit")
+	}
+
+	b, err := r.CommitObject(of)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot get commit")
+	}
+
+	return a.IsAncestor(b)
+}
+
+// resetHard resets the branch, the index and the worktree to the given commit.
+// r.mu must be held if r is shared.
+func (r *Repository) resetHard(hash CommitHash) error {
+	err := r.Worktree().Reset(&git.ResetOptions{
+		Commit: hash,
+		Mode:   git.HardReset,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "cannot reset to %s", hash)
+	}
+	return nil
+}
+
+// replay applies the changes made by the given commit onto HEAD and commits
+// them with the same message and author. If the changes conflict, then
+// ErrConflict is returned and the worktree is left untouched. If HEAD already
+// has the changes, then nothing is committed and nil is returned.
+func (r *Repository) replay(commit *gitobject.Commit) (*gitobject.Commit, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get commit tree")
+	}
+
+	parentTree := &gitobject.Tree{}
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot get parent commit")
+		}
+
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot get parent tree")
+		}
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get HEAD")
+	}
+
+	headCommit, err := r.CommitObject(head.Hash())
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get HEAD commit")
+	}
+
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get HEAD tree")
+	}
+
+	changes, err := parentTree.Diff(tree)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot diff commit")
+	}
+
+	type mergedFile struct {
+		path string
+		data []byte // nil if deleted
+	}
+
+	// Merge everything first, so that a conflict leaves the worktree intact.
+	var merged []mergedFile
+	changesHead := false
+
+	for _, change := range changes {
+		paths := map[string]struct{}{}
+		if change.From.Name != "" {
+			paths[change.From.Name] = struct{}{}
+		}
+		if change.To.Name != "" {
+			paths[change.To.Name] = struct{}{}
+		}
+
+		for path := range paths {
+			base, err := treeFile(parentTree, path)
+			if err != nil {
+				return nil, err
+			}
+
+			ours, err := treeFile(tree, path)
+			if err != nil {
+				return nil, err
+			}
+
+			theirs, err := treeFile(headTree, path)
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := r.mergeFile(path, base, ours, theirs)
+			if err != nil {
+				return nil, err
+			}
+
+			if !sameFile(data, theirs) {
+				changesHead = true
+			}
+
+			merged = append(merged, mergedFile{path, data})
+		}
+	}
+
+	if !changesHead {
+		// go-git happily makes empty commits, which would leave the branch
+		// ahead of upstream forever.
+		return nil, nil
+	}
+
+	fs := r.FS()
+	worktree := r.Worktree()
+
+	for _, file := range merged {
+		if file.data == nil {
+			if err := fs.Remove(file.path); err != nil && !os.IsNotExist(err) {
+				return nil, errors.Wrapf(err, "cannot remove %s", file.path)
+			}
+		} else {
+			if err := writeFile(r, file.path, file.data); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := worktree.Add(file.path); err != nil {
+			return nil, errors.Wrapf(err, "cannot stage %s", file.path)
+		}
+	}
+
+	hash, err := worktree.Commit(commit.Message, &git.CommitOptions{
+		Author: &commit.Author,
+		Committer: &gitobject.Signature{
+			Name:  r.Config.Author.Name,
+			Email: r.Config.Author.Email,
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot commit")
+	}
+
+	return r.CommitObject(hash)
+}
+
+// mergeFile does a three-way merge of a file. A nil slice means that the file
+// does not exist in that version. If the file was changed on both sides, then
+// the merge driver for the path is used, if any.
+func (r *Repository) mergeFile(path string, base, ours, theirs []byte) ([]byte, error) {
+	switch {
+	case sameFile(base, theirs):
+		// Only we changed the file.
+		return ours, nil
+	case sameFile(ours, theirs):
+		// Both sides made the same change.
+		return ours, nil
+	}
+
+	driver, ok := r.Config.MergeDrivers[cleanPath(path)]
+	if !ok {
+		return nil, &ConflictError{Path: path}
+	}
+
+	merged, err := driver(base, ours, theirs)
+	if err != nil {
+		return nil, &ConflictError{Path: path, Err: err}
+	}
+
+	return merged, nil
+}
+
+// cleanPath cleans the given path to be relative to the repository root, so
+// that "./a/b" and "a/b" are the same.
+func cleanPath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(p)), "/")
+}
+
+func sameFile(a, b []byte) bool {
+	return (a == nil) == (b == nil) && bytes.Equal(a, b)
+}
+
+// treeFile reads the file at the given path from the tree. If the file does
+// not exist, then nil is returned.
+func treeFile(tree *gitobject.Tree, path string) ([]byte, error) {
+	file, err := tree.File(strings.TrimPrefix(path, "/"))
+	if err != nil {
+		if err == gitobject.ErrFileNotFound || err == gitobject.ErrDirectoryNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "cannot find %s", path)
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open %s", path)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s", path)
+	}
+
+	if data == nil {
+		data = []byte{}
+	}
+
+	return data, nil
+}
+
+func writeFile(r *Repository, path string, data []byte) error {
+	f, err := r.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return errors.Wrapf(err, "cannot open %s", path)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "cannot write %s", path)
+	}
+
+	return f.Close()
+}
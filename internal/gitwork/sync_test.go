@@ -0,0 +1,316 @@
+package gitwork
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/util"
+)
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{
+		"a.txt": "a",
+		"b.txt": "b",
+	})
+	// Give the remote some history.
+	remote.Push("Update a", map[string]string{"a.txt": "a1"})
+
+	repo, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.checkoutWorkspace("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	commitFile(t, repo, "a.txt", "ours")
+	commitFile(t, repo, "c.txt", "c")
+
+	upstream := remote.Push("Update a and b", map[string]string{
+		"a.txt": "theirs",
+		"b.txt": "b1",
+	})
+
+	result, err := repo.Sync(ctx)
+	if err != nil {
+		t.Fatal("Sync:", err)
+	}
+
+	if result.Upstream != upstream {
+		t.Errorf("Upstream = %s, want %s", result.Upstream, upstream)
+	}
+	if len(result.Replayed) != 1 || len(result.Dropped) != 1 {
+		t.Fatalf("replayed %d and dropped %d commits, want 1 and 1",
+			len(result.Replayed), len(result.Dropped))
+	}
+	if !errors.Is(result.Dropped[0].Err, ErrConflict) {
+		t.Errorf("dropped commit error = %v, want ErrConflict", result.Dropped[0].Err)
+	}
+
+	parent, err := result.Replayed[0].Parent(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent.Hash != upstream {
+		t.Errorf("replayed commit is based on %s, want %s", parent.Hash, upstream)
+	}
+
+	for path, want := range map[string]string{
+		"a.txt": "theirs",
+		"b.txt": "b1",
+		"c.txt": "c",
+	} {
+		if got := readFile(t, repo, path); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+
+	// Syncing again with nothing new upstream keeps everything.
+	result, err = repo.Sync(ctx)
+	if err != nil {
+		t.Fatal("second Sync:", err)
+	}
+	if len(result.Replayed) != 0 || len(result.Dropped) != 0 {
+		t.Errorf("second Sync replayed %d and dropped %d commits, want none",
+			len(result.Replayed), len(result.Dropped))
+	}
+	if got := readFile(t, repo, "c.txt"); got != "c" {
+		t.Errorf("c.txt = %q after second Sync", got)
+	}
+}
+
+func TestSyncShallow(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+	remote.Push("Update a", map[string]string{"a.txt": "a1"})
+
+	repo, err := Clone(ctx, remote.URL(), true, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.checkoutWorkspace("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	remote.Push("Update a again", map[string]string{"a.txt": "a2"})
+
+	if _, err := repo.Sync(ctx); !errors.Is(err, ErrShallow) {
+		t.Fatalf("Sync error = %v, want ErrShallow", err)
+	}
+}
+
+func TestSyncAlreadyUpstream(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+
+	repo, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.checkoutWorkspace("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	squashed := commitFile(t, repo, "a.txt", "ours")
+	commitFile(t, repo, "b.txt", "b")
+
+	// Upstream applies the first change under a different commit, as a
+	// squash merge would.
+	remote.Push("Squash a", map[string]string{"a.txt": "ours"})
+
+	result, err := repo.Sync(ctx)
+	if err != nil {
+		t.Fatal("Sync:", err)
+	}
+	if len(result.Replayed) != 1 || len(result.Upstreamed) != 1 || len(result.Dropped) != 0 {
+		t.Fatalf("replayed %d, upstreamed %d and dropped %d commits, want 1, 1 and 0",
+			len(result.Replayed), len(result.Upstreamed), len(result.Dropped))
+	}
+	if result.Upstreamed[0].Hash != squashed {
+		t.Errorf("upstreamed commit is %s, want %s", result.Upstreamed[0].Hash, squashed)
+	}
+
+	ahead, err := repo.CommitsAhead("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ahead) != 1 || ahead[0].Message != "Write b.txt" {
+		t.Errorf("%d commits ahead after Sync, want only the one writing b.txt", len(ahead))
+	}
+
+	// Once the rest is upstream too, the branch is no longer ahead.
+	remote.Push("Squash b", map[string]string{"b.txt": "b"})
+
+	result, err = repo.Sync(ctx)
+	if err != nil {
+		t.Fatal("second Sync:", err)
+	}
+	if len(result.Replayed) != 0 || len(result.Upstreamed) != 1 {
+		t.Errorf("second Sync replayed %d and upstreamed %d commits, want 0 and 1",
+			len(result.Replayed), len(result.Upstreamed))
+	}
+
+	ahead, err = repo.CommitsAhead("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ahead) != 0 {
+		t.Errorf("%d commits ahead after everything was squashed upstream", len(ahead))
+	}
+	if got := readFile(t, repo, "b.txt"); got != "b" {
+		t.Errorf("b.txt = %q, want b", got)
+	}
+}
+
+func TestSyncReportsDiscardedChanges(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"a.txt": "a"})
+
+	repo, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.checkoutWorkspace("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := util.WriteFile(repo.FS(), "a.txt", []byte("uncommitted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	remote.Push("Add b", map[string]string{"b.txt": "b"})
+
+	result, err := repo.Sync(ctx)
+	if err != nil {
+		t.Fatal("Sync:", err)
+	}
+
+	if len(result.Discarded) != 1 || result.Discarded[0] != "a.txt" {
+		t.Errorf("Discarded = %q, want [a.txt]", result.Discarded)
+	}
+}
+
+func TestSyncDuringEdit(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"n.txt": "0"})
+
+	repo, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.checkoutWorkspace("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	remote.Push("Add b", map[string]string{"b.txt": "b"})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			_, err := repo.Edit(func(edit *Edit) (string, string, error) {
+				edit.WriteFile("n.txt", []byte{byte('a' + i)})
+				return "Write n", "", nil
+			})
+			if err != nil && !errors.Is(err, ErrNoChanges) {
+				errs <- err
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.Sync(ctx); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	status, err := repo.Worktree().Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.IsClean() {
+		t.Errorf("worktree is not clean after concurrent edits and syncs:\n%s", status)
+	}
+}
+
+func TestSyncDuringPush(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t, map[string]string{"n.txt": "0"})
+
+	repo, err := Clone(ctx, remote.URL(), false, nil, InMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.checkoutWorkspace("work"); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 30)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			_, err := repo.Edit(func(edit *Edit) (string, string, error) {
+				edit.WriteFile("n.txt", []byte{byte('a' + i)})
+				return "Write n", "", nil
+			})
+			if err != nil && !errors.Is(err, ErrNoChanges) {
+				errs <- err
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.Sync(ctx); err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := repo.PushWithLease(ctx); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if err := repo.PushWithLease(ctx); err != nil {
+		t.Fatal("final push:", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := remote.Head("work"); got != head.Hash() {
+		t.Errorf("remote work = %s, want HEAD %s", got, head.Hash())
+	}
+
+	status, err := repo.Worktree().Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.IsClean() {
+		t.Errorf("worktree is not clean after concurrent edits and pushes:\n%s", status)
+	}
+}
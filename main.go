@@ -2,12 +2,19 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/diamondburned/arikawa/v3/state"
+	"github.com/diamondburned/officer-data/acmcsuf"
 	"github.com/diamondburned/officer-data/bot"
+	"github.com/diamondburned/officer-data/internal/forge"
 	"github.com/diamondburned/officer-data/internal/gitwork"
 	"github.com/pkg/errors"
 )
@@ -35,16 +42,79 @@ func run(ctx context.Context) error {
 	gitAuthor.Name = envOr(gitAuthor.Name, "GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME")
 	gitAuthor.Email = envOr(gitAuthor.Email, "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL")
 
-	gitworkDir := envOr(os.TempDir(), "GITWORK_DIR")
+	gitworkDir := envOr(filepath.Join(os.TempDir(), "officer-data"), "GITWORK_DIR")
 	gitworkRemote := envOr("https://github.com/EthanThatOneKid/acmcsuf.com.git", "GITWORK_REMOTE")
 
 	gitPool, err := gitwork.NewPool(gitworkDir, gitworkRemote)
 	if err != nil {
 		return errors.Wrap(err, "cannot create git pool")
 	}
+	defer func() {
+		if err := gitPool.Flush(); err != nil {
+			log.Println("cannot save git pool index:", err)
+		}
+	}()
 	gitPool.Author = gitAuthor
 
-	handler := bot.New(state, gitPool)
+	gitPool.Auth, err = gitAuth(gitworkRemote)
+	if err != nil {
+		return errors.Wrap(err, "cannot set up git authentication")
+	}
+	gitPool.MergeDrivers = map[string]gitwork.MergeDriver{
+		acmcsuf.OfficersJSONPath: acmcsuf.MergeOfficersJSON,
+	}
+
+	if maxIdle := os.Getenv("GITWORK_MAX_IDLE"); maxIdle != "" {
+		gitPool.MaxIdle, err = time.ParseDuration(maxIdle)
+		if err != nil {
+			return errors.Wrap(err, "invalid $GITWORK_MAX_IDLE")
+		}
+	}
+
+	if budget := os.Getenv("GITWORK_DISK_BUDGET"); budget != "" {
+		gitPool.DiskBudget, err = parseBytes(budget)
+		if err != nil {
+			return errors.Wrap(err, "invalid $GITWORK_DISK_BUDGET")
+		}
+	}
+
+	// The mirror is opt-in, since new workspaces start from it and are only
+	// as fresh as its last update. Without it, every workspace is cloned from
+	// the remote.
+	var mirrorInterval time.Duration
+	if interval := os.Getenv("GITWORK_MIRROR_INTERVAL"); interval != "" {
+		mirrorInterval, err = time.ParseDuration(interval)
+		if err != nil {
+			return errors.Wrap(err, "invalid $GITWORK_MIRROR_INTERVAL")
+		}
+	}
+	gitPool.Mirror = mirrorInterval > 0
+
+	var prForge forge.Forge
+	if token := forgeToken(gitPool.Auth); token != nil {
+		owner, repo, err := forge.ParseGitHubRemote(gitworkRemote)
+		if err != nil {
+			return errors.Wrap(err, "cannot use GitHub for pull requests")
+		}
+		prForge = forge.NewGitHub(owner, repo, token)
+	} else {
+		log.Println("no GitHub App, $GITWORK_TOKEN or $GITHUB_TOKEN is set, /officer pr will be disabled")
+	}
+
+	handler := bot.New(state, gitPool, prForge)
+
+	gitPool.Keep = handler.KeepWorkspace
+	gitPool.StartJanitor(ctx, time.Hour)
+	if gitPool.Mirror {
+		gitPool.StartMirror(ctx, mirrorInterval)
+	}
+
+	if roles := os.Getenv("EDITOR_ROLES"); roles != "" {
+		handler.Editors.Roles, err = bot.ParseEditorRoles(roles)
+		if err != nil {
+			return errors.Wrap(err, "invalid $EDITOR_ROLES")
+		}
+	}
 	state.AddInteractionHandler(handler)
 
 	if err := handler.OverwriteCommands(); err != nil {
@@ -54,6 +124,118 @@ func run(ctx context.Context) error {
 	return state.Connect(ctx)
 }
 
+// parseBytes parses a size such as "512M" or "2G" into bytes. Sizes without a
+// suffix are in bytes.
+func parseBytes(s string) (int64, error) {
+	units := map[string]int64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30, "T": 1 << 40}
+
+	s = strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	unit := int64(1)
+	if n := len(s); n > 0 {
+		if u, ok := units[s[n-1:]]; ok {
+			unit = u
+			s = s[:n-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return n * unit, nil
+}
+
+// gitAuth returns the credentials for the git remote from the environment.
+// The first of these that is set is used:
+//
+//   - $GITHUB_APP_ID, $GITHUB_APP_INSTALLATION_ID and
+//     $GITHUB_APP_PRIVATE_KEY (the path to the app's private key)
+//   - $GITWORK_SSH_KEY (the path to a private key), optionally with
+//     $GITWORK_SSH_KEY_PASSPHRASE
+//   - $GITWORK_SSH_AGENT=1, which uses the SSH agent at $SSH_AUTH_SOCK
+//   - $GITWORK_TOKEN, optionally with $GITWORK_TOKEN_USER
+//   - $GITHUB_TOKEN, if the remote is HTTPS
+//
+// A nil Auth is returned if none are set. Credentials are never logged.
+func gitAuth(remoteURL string) (gitwork.Auth, error) {
+	sshUser := envOr("git", "GITWORK_SSH_USER")
+
+	switch {
+	case os.Getenv("GITHUB_APP_ID") != "":
+		appID, err := strconv.ParseInt(os.Getenv("GITHUB_APP_ID"), 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid $GITHUB_APP_ID")
+		}
+
+		installationID, err := strconv.ParseInt(os.Getenv("GITHUB_APP_INSTALLATION_ID"), 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid $GITHUB_APP_INSTALLATION_ID")
+		}
+
+		keyPath := os.Getenv("GITHUB_APP_PRIVATE_KEY")
+		if keyPath == "" {
+			return nil, errors.New("$GITHUB_APP_PRIVATE_KEY is not set")
+		}
+
+		key, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot read $GITHUB_APP_PRIVATE_KEY")
+		}
+
+		auth, err := gitwork.NewGitHubAppAuth(appID, installationID, key)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid $GITHUB_APP_PRIVATE_KEY")
+		}
+
+		log.Println("git: authenticating as GitHub App", appID)
+		return auth, nil
+
+	case os.Getenv("GITWORK_SSH_KEY") != "":
+		log.Println("git: authenticating with an SSH key")
+		return gitwork.NewSSHKeyAuth(
+			sshUser,
+			os.Getenv("GITWORK_SSH_KEY"),
+			os.Getenv("GITWORK_SSH_KEY_PASSPHRASE"),
+		), nil
+
+	case os.Getenv("GITWORK_SSH_AGENT") != "":
+		log.Println("git: authenticating with the SSH agent")
+		return gitwork.SSHAgentAuth(sshUser), nil
+
+	case os.Getenv("GITWORK_TOKEN") != "":
+		log.Println("git: authenticating with $GITWORK_TOKEN")
+		return gitwork.TokenAuth{
+			Username: os.Getenv("GITWORK_TOKEN_USER"),
+			Token:    os.Getenv("GITWORK_TOKEN"),
+		}, nil
+
+	case os.Getenv("GITHUB_TOKEN") != "" && strings.HasPrefix(remoteURL, "https://"):
+		log.Println("git: authenticating with $GITHUB_TOKEN")
+		return gitwork.TokenAuth{Token: os.Getenv("GITHUB_TOKEN")}, nil
+
+	default:
+		log.Println("git: no credentials are set, pushing will likely fail")
+		return nil, nil
+	}
+}
+
+// forgeToken returns the token for the GitHub API: the GitHub App's
+// installation token or the token that git authenticates with, or
+// $GITHUB_TOKEN otherwise. It returns nil if there's none.
+func forgeToken(auth gitwork.Auth) forge.TokenSource {
+	switch auth := auth.(type) {
+	case *gitwork.GitHubAppAuth:
+		return auth.Token
+	case gitwork.TokenAuth:
+		return forge.StaticToken(auth.Token)
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return forge.StaticToken(token)
+	}
+	return nil
+}
+
 func envOr(def string, keys ...string) string {
 	for _, key := range keys {
 		if v := os.Getenv(key); v != "" {